@@ -0,0 +1,210 @@
+// Command contractcheck guards the wire formats shared with the Python
+// analytic-service: "export" writes every registered RPC contract's JSON
+// Schema to a file under a contracts/ directory, and every registered
+// standalone type's schema (see internal/contract/types.go) under a
+// schemas/ directory; "check" re-derives those schemas from the live Go
+// structs and fails if either set has drifted from what's on disk without a
+// matching version bump, so a PR that silently breaks the Python consumer
+// fails CI instead of shipping.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/hoggir/re-path/redirect-service/internal/contract"
+)
+
+// draftSchemaURI identifies the JSON Schema dialect every exported document
+// is written against.
+const draftSchemaURI = "https://json-schema.org/draft/2020-12/schema"
+
+// exportedContract is the on-disk shape of a contracts/<name>.json file.
+type exportedContract struct {
+	SchemaURI string           `json:"$schema"`
+	Version   string           `json:"version"`
+	Request   *contract.Schema `json:"request"`
+	Response  *contract.Schema `json:"response"`
+}
+
+// exportedType is the on-disk shape of a schemas/<name>.schema.json file.
+type exportedType struct {
+	SchemaURI  string           `json:"$schema"`
+	Version    string           `json:"version"`
+	Definition *contract.Schema `json:"definition"`
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	fs := flag.NewFlagSet(os.Args[1], flag.ExitOnError)
+	dir := fs.String("dir", "contracts", "directory of exported RPC contract schemas, one per contract name")
+	typesDir := fs.String("types-dir", "schemas", "directory of exported standalone type schemas, one per type name")
+	fs.Parse(os.Args[2:])
+
+	switch os.Args[1] {
+	case "export":
+		if err := export(*dir); err != nil {
+			fmt.Fprintln(os.Stderr, "contractcheck:", err)
+			os.Exit(1)
+		}
+		if err := exportTypes(*typesDir); err != nil {
+			fmt.Fprintln(os.Stderr, "contractcheck:", err)
+			os.Exit(1)
+		}
+	case "check":
+		drifted, err := check(*dir)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "contractcheck:", err)
+			os.Exit(1)
+		}
+		typeDrifted, err := checkTypes(*typesDir)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "contractcheck:", err)
+			os.Exit(1)
+		}
+		drifted = append(drifted, typeDrifted...)
+
+		if len(drifted) > 0 {
+			for _, msg := range drifted {
+				fmt.Fprintln(os.Stderr, "contractcheck:", msg)
+			}
+			os.Exit(1)
+		}
+		fmt.Println("contractcheck: all registered contracts and types match their exported schemas")
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: contractcheck <export|check> [-dir contracts] [-types-dir schemas]")
+}
+
+func export(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create %s: %w", dir, err)
+	}
+
+	for _, reg := range contract.All() {
+		data, err := json.MarshalIndent(exportedContract{
+			SchemaURI: draftSchemaURI,
+			Version:   reg.Version,
+			Request:   reg.RequestSchema,
+			Response:  reg.ResponseSchema,
+		}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("%s: marshal schema: %w", reg.Name, err)
+		}
+
+		path := filepath.Join(dir, reg.Name+".json")
+		if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+			return fmt.Errorf("%s: write %s: %w", reg.Name, path, err)
+		}
+	}
+	return nil
+}
+
+// exportTypes writes every standalone type schema (see
+// internal/contract/types.go) to dir/<name>.schema.json.
+func exportTypes(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create %s: %w", dir, err)
+	}
+
+	for _, reg := range contract.AllTypes() {
+		data, err := json.MarshalIndent(exportedType{
+			SchemaURI:  draftSchemaURI,
+			Version:    reg.Version,
+			Definition: reg.Schema,
+		}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("%s: marshal schema: %w", reg.Name, err)
+		}
+
+		path := filepath.Join(dir, reg.Name+".schema.json")
+		if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+			return fmt.Errorf("%s: write %s: %w", reg.Name, path, err)
+		}
+	}
+	return nil
+}
+
+// check compares every registered contract against its exported schema and
+// returns a human-readable message per drifted contract whose exported
+// version wasn't also bumped - an intentional version bump alongside a
+// format change is allowed; a silent one is not.
+func check(dir string) ([]string, error) {
+	var drifted []string
+
+	for _, reg := range contract.All() {
+		path := filepath.Join(dir, reg.Name+".json")
+		data, err := os.ReadFile(path)
+		if err != nil {
+			drifted = append(drifted, fmt.Sprintf("%s: no exported schema at %s (run `contractcheck export` and commit it)", reg.Name, path))
+			continue
+		}
+
+		var exported exportedContract
+		if err := json.Unmarshal(data, &exported); err != nil {
+			drifted = append(drifted, fmt.Sprintf("%s: %s is not a valid exported contract: %v", reg.Name, path, err))
+			continue
+		}
+
+		sameRequest := reflect.DeepEqual(exported.Request, reg.RequestSchema)
+		sameResponse := reflect.DeepEqual(exported.Response, reg.ResponseSchema)
+		if sameRequest && sameResponse {
+			continue
+		}
+
+		if exported.Version == reg.Version {
+			drifted = append(drifted, fmt.Sprintf(
+				"%s: wire format changed but the contract version is still %s - bump it and run `contractcheck export`",
+				reg.Name, reg.Version,
+			))
+		}
+	}
+
+	return drifted, nil
+}
+
+// checkTypes is check's counterpart for standalone type schemas.
+func checkTypes(dir string) ([]string, error) {
+	var drifted []string
+
+	for _, reg := range contract.AllTypes() {
+		path := filepath.Join(dir, reg.Name+".schema.json")
+		data, err := os.ReadFile(path)
+		if err != nil {
+			drifted = append(drifted, fmt.Sprintf("%s: no exported schema at %s (run `contractcheck export` and commit it)", reg.Name, path))
+			continue
+		}
+
+		var exported exportedType
+		if err := json.Unmarshal(data, &exported); err != nil {
+			drifted = append(drifted, fmt.Sprintf("%s: %s is not a valid exported type schema: %v", reg.Name, path, err))
+			continue
+		}
+
+		if reflect.DeepEqual(exported.Definition, reg.Schema) {
+			continue
+		}
+
+		if exported.Version == reg.Version {
+			drifted = append(drifted, fmt.Sprintf(
+				"%s: schema changed but the type version is still %s - bump it and run `contractcheck export`",
+				reg.Name, reg.Version,
+			))
+		}
+	}
+
+	return drifted, nil
+}
@@ -9,6 +9,8 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+
+	"github.com/hoggir/re-path/redirect-service/internal/metrics"
 )
 
 // @title           Re:Path Redirect Service API
@@ -30,15 +32,20 @@ import (
 func main() {
 	srv, err := InitializeApp()
 	if err != nil {
+		// No logger.Logger exists yet - InitializeApp failed before
+		// wiring one - so this is the one place main.go still reaches
+		// for the stdlib log package.
 		log.Fatalf("❌ Failed to initialize app: %v", err)
 	}
+	logger := srv.Logger
+
 	defer func() {
-		log.Println("🧹 Cleaning up resources...")
+		logger.Info("🧹 Cleaning up resources...")
 		if err := srv.MongoDB.Close(); err != nil {
-			log.Printf("Error closing MongoDB: %v", err)
+			logger.Error("Error closing MongoDB", "error", err)
 		}
 		if err := srv.Redis.Close(); err != nil {
-			log.Printf("Error closing Redis: %v", err)
+			logger.Error("Error closing Redis", "error", err)
 		}
 	}()
 
@@ -55,31 +62,88 @@ func main() {
 	serverErrors := make(chan error, 1)
 
 	go func() {
-		log.Printf("🚀 Redirect service starting on port %s...", port)
+		logger.Info("🚀 Redirect service starting", "port", port)
 		serverErrors <- httpServer.ListenAndServe()
 	}()
 
+	// A non-empty METRICS_PORT runs /metrics on its own listener instead of
+	// the main router (see server.registerPublicRoutes), so it can be
+	// firewalled off from public traffic independently of the main port.
+	var metricsServer *http.Server
+	if metricsPort := srv.Config.Metrics.Port; metricsPort != "" {
+		metricsMux := http.NewServeMux()
+		metricsMux.Handle("/metrics", metrics.Handler())
+		metricsServer = &http.Server{
+			Addr:    fmt.Sprintf(":%s", metricsPort),
+			Handler: metricsMux,
+		}
+
+		go func() {
+			logger.Info("📈 Metrics server starting", "port", metricsPort)
+			if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Error("❌ Metrics server error", "error", err)
+			}
+		}()
+	}
+
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
 
 	select {
 	case err := <-serverErrors:
-		log.Fatalf("❌ Server error: %v", err)
+		logger.Fatal("❌ Server error", "error", err)
 
 	case sig := <-shutdown:
-		log.Printf("\n⚠️  Received signal: %v, starting graceful shutdown...", sig)
+		logger.Info("⚠️  Received signal, starting graceful shutdown...", "signal", sig.String())
 
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
+		// Flip readiness to failing first, before anything else: a load
+		// balancer polling /readyz should stop sending new traffic here
+		// before httpServer.Shutdown starts refusing it outright.
+		if srv.HealthService != nil {
+			srv.HealthService.Shutdown()
+		}
+
+		if srv.ClickEventConsumer != nil {
+			if err := srv.ClickEventConsumer.Shutdown(ctx); err != nil {
+				logger.Error("❌ Error draining click event consumer", "error", err)
+			}
+		}
+
+		if srv.ClickEventIngester != nil {
+			if err := srv.ClickEventIngester.Shutdown(ctx); err != nil {
+				logger.Error("❌ Error draining click event ingester", "error", err)
+			}
+		}
+
 		if err := httpServer.Shutdown(ctx); err != nil {
-			log.Printf("❌ Error during shutdown: %v", err)
+			logger.Error("❌ Error during shutdown", "error", err)
 			if err := httpServer.Close(); err != nil {
-				log.Fatalf("❌ Could not stop server: %v", err)
+				logger.Fatal("❌ Could not stop server", "error", err)
+			}
+		}
+
+		if metricsServer != nil {
+			if err := metricsServer.Shutdown(ctx); err != nil {
+				logger.Error("❌ Error shutting down metrics server", "error", err)
+			}
+		}
+
+		if srv.EventBus != nil {
+			if err := srv.EventBus.Close(); err != nil {
+				logger.Error("❌ Error closing event bus", "error", err)
+			}
+		}
+
+		if srv.TracerProvider != nil {
+			if err := srv.TracerProvider.Shutdown(ctx); err != nil {
+				logger.Error("Error shutting down tracer provider", "error", err)
 			}
 		}
 
-		log.Println("✅ Server stopped successfully")
-		log.Println("👋 Shutdown complete")
+		logger.Info("✅ Server stopped successfully")
+		logger.Info("👋 Shutdown complete")
 	}
 }
@@ -7,37 +7,60 @@ import (
 	"github.com/google/wire"
 	"github.com/hoggir/re-path/redirect-service/internal/config"
 	"github.com/hoggir/re-path/redirect-service/internal/database"
+	"github.com/hoggir/re-path/redirect-service/internal/eventbus"
 	"github.com/hoggir/re-path/redirect-service/internal/handler"
 	"github.com/hoggir/re-path/redirect-service/internal/logger"
 	"github.com/hoggir/re-path/redirect-service/internal/repository"
 	"github.com/hoggir/re-path/redirect-service/internal/server"
 	"github.com/hoggir/re-path/redirect-service/internal/service"
+	"github.com/hoggir/re-path/redirect-service/internal/storage"
+	"github.com/hoggir/re-path/redirect-service/internal/tracing"
 )
 
 func InitializeApp() (*server.Server, error) {
 	wire.Build(
 		config.Load,
 		logger.NewLogger,
+		tracing.NewProvider,
 
 		database.NewMongoDB,
 		database.NewRedis,
 		database.NewRabbitMQ,
 
+		eventbus.New,
+
+		storage.NewManager,
+		storage.NewURLStore,
+
 		repository.NewURLRepository,
 		repository.NewClickEventRepository,
 
 		service.NewCacheKeyGenerator,
 		service.NewCacheService,
+		service.NewCacheInvalidator,
+		service.NewRateLimiter,
+		service.NewSweeper,
+		service.NewClickCountBatcher,
+		service.NewClickEventIngester,
 		service.NewGeoIPService,
+		service.NewGeoIPUpdater,
 		service.NewRabbitMQRPCService,
+		service.NewRPCService,
+		service.NewRabbitMQService,
+		service.NewBotDetector,
+		service.NewClickEventConsumer,
 		service.NewRedirectService,
 		service.NewClickEventService,
+		service.NewDashboardClient,
 		service.NewDashboardService,
 		service.NewJWTService,
+		service.NewHealthService,
 
 		handler.NewRedirectHandler,
 		handler.NewHealthHandler,
 		handler.NewDashboardHandler,
+		handler.NewAdminHandler,
+		handler.NewAuthHandler,
 
 		server.NewHandlers,
 		server.NewMiddlewares,
@@ -15,11 +15,12 @@ func NewUserHandler(service *users.UserService) *UserHandler {
 }
 
 func (h *UserHandler) GetAllUsers(c *gin.Context) {
-	users, err := h.UserService.GetAll()
+	all, err := h.UserService.GetAll()
 	if err != nil {
 		response.InternalError(c, "Failed to fetch users", err.Error())
 		return
 	}
 
-	response.OK(c, "Users fetched successfully", users)
+	page, perPage, start, end := response.PageFromQuery(c, len(all))
+	response.Paginated(c, "Users fetched successfully", all[start:end], response.NewPagination(page, perPage, int64(len(all))))
 }
@@ -0,0 +1,98 @@
+package response
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hoggir/re-path/redirect-service/internal/reqctx"
+)
+
+const defaultPerPage = 20
+
+// Meta rides alongside every envelope (success, error, and problem) so a
+// client can correlate a response back to its request and, for list
+// endpoints, page through the rest of the result set.
+type Meta struct {
+	RequestID  string      `json:"request_id,omitempty"`
+	Timestamp  time.Time   `json:"timestamp"`
+	DurationMs int64       `json:"duration_ms"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+// Pagination describes one page of a larger result set. TotalPages is
+// ceil(Total/PerPage), never less than 1; callers build it with NewPagination rather than
+// computing that by hand.
+type Pagination struct {
+	Page       int   `json:"page"`
+	PerPage    int   `json:"per_page"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"total_pages"`
+}
+
+// NewPagination derives TotalPages from total and perPage. perPage <= 0 is
+// treated as a single page covering the whole result set.
+func NewPagination(page, perPage int, total int64) Pagination {
+	totalPages := 1
+	if perPage > 0 {
+		totalPages = int((total + int64(perPage) - 1) / int64(perPage))
+		if totalPages < 1 {
+			totalPages = 1
+		}
+	}
+	return Pagination{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+}
+
+// PageFromQuery reads "page" and "per_page" off the request's query string
+// (a missing, non-numeric, or non-positive value falls back to 1 and
+// defaultPerPage respectively), and
+// slices [start, end) out of total items. Handlers that hold their full
+// result set in memory - UserHandler.GetAllUsers and its kin - use this
+// instead of each re-deriving slice bounds by hand.
+func PageFromQuery(c *gin.Context, total int) (page, perPage, start, end int) {
+	page = 1
+	if v, err := strconv.Atoi(c.Query("page")); err == nil && v > 0 {
+		page = v
+	}
+
+	perPage = defaultPerPage
+	if v, err := strconv.Atoi(c.Query("per_page")); err == nil && v > 0 {
+		perPage = v
+	}
+
+	start = (page - 1) * perPage
+	if start > total {
+		start = total
+	}
+
+	end = start + perPage
+	if end > total {
+		end = total
+	}
+
+	return page, perPage, start, end
+}
+
+// buildMeta reads the request ID and start time RequestContextMiddleware
+// stashed on the request context. Both are best-effort: a handler invoked
+// outside that middleware (a unit test, say) just gets a zero DurationMs
+// and an empty RequestID instead of an error.
+func buildMeta(c *gin.Context) *Meta {
+	ctx := c.Request.Context()
+
+	meta := &Meta{
+		RequestID: reqctx.RequestID(ctx),
+		Timestamp: time.Now().UTC(),
+	}
+
+	if start, ok := reqctx.StartTime(ctx); ok {
+		meta.DurationMs = time.Since(start).Milliseconds()
+	}
+
+	return meta
+}
@@ -0,0 +1,126 @@
+package response
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProblemContentType is the RFC 7807 media type. A request whose Accept
+// header lists it gets an error body shaped as a Problem instead of an
+// ErrorResponse (see wantsProblemJSON).
+const ProblemContentType = "application/problem+json"
+
+// ErrCode is a stable, machine-readable error identifier - distinct from the
+// HTTP status, which can repeat across unrelated failures - so clients can
+// branch on the reason rather than parsing Message.
+type ErrCode string
+
+const (
+	ErrCodeValidation   ErrCode = "VALIDATION_ERROR"
+	ErrCodeNotFound     ErrCode = "NOT_FOUND"
+	ErrCodeUnauthorized ErrCode = "UNAUTHORIZED"
+	ErrCodeForbidden    ErrCode = "FORBIDDEN"
+	ErrCodeRateLimited  ErrCode = "RATE_LIMITED"
+	ErrCodeUpstream     ErrCode = "UPSTREAM_ERROR"
+	ErrCodeInternal     ErrCode = "INTERNAL_ERROR"
+)
+
+// problemTitle gives each ErrCode a short, stable summary for the RFC 7807
+// "title" field, which - unlike Detail - is meant to stay constant across
+// occurrences of the same problem type.
+var problemTitle = map[ErrCode]string{
+	ErrCodeValidation:   "Validation Failed",
+	ErrCodeNotFound:     "Resource Not Found",
+	ErrCodeUnauthorized: "Unauthorized",
+	ErrCodeForbidden:    "Forbidden",
+	ErrCodeRateLimited:  "Too Many Requests",
+	ErrCodeUpstream:     "Upstream Service Error",
+	ErrCodeInternal:     "Internal Server Error",
+}
+
+// problemTypeBase namespaces the RFC 7807 "type" URI; it doesn't need to
+// resolve, only to be a stable, de-referenceable-looking identifier clients
+// can match on.
+const problemTypeBase = "https://re-path.dev/problems/"
+
+// Problem is an RFC 7807 application/problem+json error body.
+type Problem struct {
+	Type     string  `json:"type"`
+	Title    string  `json:"title"`
+	Status   int     `json:"status"`
+	Detail   string  `json:"detail,omitempty"`
+	Instance string  `json:"instance,omitempty"`
+	Code     ErrCode `json:"code"`
+	Meta     *Meta   `json:"meta,omitempty"`
+}
+
+// wantsProblemJSON reports whether the request's Accept header lists
+// application/problem+json at all; q-values and the position of other media
+// types in the header are not compared.
+func wantsProblemJSON(c *gin.Context) bool {
+	return strings.Contains(c.GetHeader("Accept"), ProblemContentType)
+}
+
+// ProblemCode writes statusCode as an RFC 7807 problem with code and detail,
+// regardless of Accept negotiation. Use when a caller already knows it wants
+// problem+json (e.g. the panic-recovery middleware); ErrorCode is the
+// Accept-negotiated entry point ordinary handlers should prefer.
+func ProblemCode(c *gin.Context, statusCode int, code ErrCode, detail string) {
+	title, ok := problemTitle[code]
+	if !ok {
+		title = problemTitle[ErrCodeInternal]
+	}
+
+	c.Header("Content-Type", ProblemContentType)
+	c.JSON(statusCode, Problem{
+		Type:     problemTypeBase + strings.ToLower(string(code)),
+		Title:    title,
+		Status:   statusCode,
+		Detail:   detail,
+		Instance: c.Request.URL.Path,
+		Code:     code,
+		Meta:     buildMeta(c),
+	})
+}
+
+// ErrorCode writes statusCode as either an ErrorResponse or, when the caller
+// negotiated application/problem+json via Accept, a Problem - both carrying
+// code so the client can branch on a stable identifier either way.
+func ErrorCode(c *gin.Context, statusCode int, code ErrCode, message string, err interface{}) {
+	if wantsProblemJSON(c) {
+		ProblemCode(c, statusCode, code, message)
+		return
+	}
+
+	c.JSON(statusCode, ErrorResponse{
+		Status:  "error",
+		Code:    statusCode,
+		Message: message,
+		Errors:  err,
+		ErrCode: code,
+		Meta:    buildMeta(c),
+	})
+}
+
+// defaultCodeForStatus infers an ErrCode for call sites (BadRequest,
+// NotFound, InternalError, ...) that predate the error catalog and don't
+// pass one explicitly.
+func defaultCodeForStatus(statusCode int) ErrCode {
+	switch statusCode {
+	case 400, 422:
+		return ErrCodeValidation
+	case 401:
+		return ErrCodeUnauthorized
+	case 403:
+		return ErrCodeForbidden
+	case 404:
+		return ErrCodeNotFound
+	case 429:
+		return ErrCodeRateLimited
+	case 502, 503, 504:
+		return ErrCodeUpstream
+	default:
+		return ErrCodeInternal
+	}
+}
@@ -11,6 +11,7 @@ type SuccessResponse struct {
 	Code    int         `json:"code"`
 	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
+	Meta    *Meta       `json:"meta,omitempty"`
 }
 
 type ErrorResponse struct {
@@ -18,6 +19,8 @@ type ErrorResponse struct {
 	Code    int         `json:"code"`
 	Message string      `json:"message"`
 	Errors  interface{} `json:"errors,omitempty"`
+	ErrCode ErrCode     `json:"error_code,omitempty"`
+	Meta    *Meta       `json:"meta,omitempty"`
 }
 
 // ---- SUCCESS WRAPPER ----
@@ -27,19 +30,34 @@ func Success(c *gin.Context, code int, message string, data interface{}) {
 		Code:    code,
 		Message: message,
 		Data:    data,
+		Meta:    buildMeta(c),
 	})
 }
 
-// ---- ERROR WRAPPER ----
-func Error(c *gin.Context, code int, message string, err interface{}) {
-	c.JSON(code, ErrorResponse{
-		Status:  "error",
-		Code:    code,
+// Paginated writes a 200 SuccessResponse carrying page alongside data, so
+// list handlers (UserHandler.GetAllUsers and friends) don't each have to
+// build their own Meta.
+func Paginated(c *gin.Context, message string, data interface{}, page Pagination) {
+	meta := buildMeta(c)
+	meta.Pagination = &page
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Status:  "success",
+		Code:    http.StatusOK,
 		Message: message,
-		Errors:  err,
+		Data:    data,
+		Meta:    meta,
 	})
 }
 
+// ---- ERROR WRAPPER ----
+// Error negotiates on the request's Accept header: a client asking for
+// application/problem+json gets an RFC 7807 Problem instead of an
+// ErrorResponse, both carrying the same ErrCode inferred from code.
+func Error(c *gin.Context, code int, message string, err interface{}) {
+	ErrorCode(c, code, defaultCodeForStatus(code), message, err)
+}
+
 // ---- Shortcut Helpers ----
 func OK(c *gin.Context, message string, data interface{}) {
 	Success(c, http.StatusOK, message, data)
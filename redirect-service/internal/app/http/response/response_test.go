@@ -0,0 +1,89 @@
+package response
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func TestNewPagination_TotalPages(t *testing.T) {
+	cases := []struct {
+		total   int64
+		perPage int
+		want    int
+	}{
+		{total: 0, perPage: 20, want: 1},
+		{total: 20, perPage: 20, want: 1},
+		{total: 21, perPage: 20, want: 2},
+		{total: 100, perPage: 0, want: 1},
+	}
+
+	for _, tc := range cases {
+		got := NewPagination(1, tc.perPage, tc.total).TotalPages
+		if got != tc.want {
+			t.Errorf("NewPagination(1, %d, %d).TotalPages = %d, want %d", tc.perPage, tc.total, got, tc.want)
+		}
+	}
+}
+
+func TestPageFromQuery_ClampsToTotal(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/?page=3&per_page=10", nil)
+
+	page, perPage, start, end := PageFromQuery(c, 25)
+
+	if page != 3 || perPage != 10 {
+		t.Fatalf("PageFromQuery() = page %d, perPage %d, want 3, 10", page, perPage)
+	}
+	if start != 20 || end != 25 {
+		t.Errorf("PageFromQuery() bounds = [%d, %d), want [20, 25)", start, end)
+	}
+}
+
+func TestPageFromQuery_DefaultsOnInvalidInput(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/?page=-1&per_page=bogus", nil)
+
+	page, perPage, start, end := PageFromQuery(c, 5)
+
+	if page != 1 || perPage != defaultPerPage {
+		t.Fatalf("PageFromQuery() = page %d, perPage %d, want 1, %d", page, perPage, defaultPerPage)
+	}
+	if start != 0 || end != 5 {
+		t.Errorf("PageFromQuery() bounds = [%d, %d), want [0, 5)", start, end)
+	}
+}
+
+func TestError_NegotiatesProblemJSON(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/users", nil)
+	c.Request.Header.Set("Accept", ProblemContentType)
+
+	Error(c, http.StatusNotFound, "not found", nil)
+
+	if ct := w.Header().Get("Content-Type"); ct != ProblemContentType {
+		t.Errorf("Content-Type = %q, want %q", ct, ProblemContentType)
+	}
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestError_DefaultsToJSONEnvelope(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/users", nil)
+
+	Error(c, http.StatusNotFound, "not found", nil)
+
+	if ct := w.Header().Get("Content-Type"); ct == ProblemContentType {
+		t.Errorf("Content-Type = %q, want a plain JSON response", ct)
+	}
+}
@@ -0,0 +1,51 @@
+package contract
+
+import (
+	"reflect"
+
+	"github.com/hoggir/re-path/redirect-service/internal/domain"
+)
+
+// dashboardResponseSchema narrows DashboardResponse.Status into a oneOf of
+// one branch per value it can take (domain.DashboardResponse.IsSuccess /
+// IsError / IsLimited), so Message is only required on the "error" branch
+// instead of being either always-optional or always-required.
+var dashboardResponseSchema = WithDiscriminator(
+	FromType(reflect.TypeOf(domain.DashboardResponse{})),
+	"status",
+	map[string][]string{
+		"success": nil,
+		"error":   {"message"},
+		"limited": nil,
+	},
+)
+
+// dashboardRequestSchema is shared by every registered version of this
+// contract: only the response shape has changed so far.
+var dashboardRequestSchema = FromType(reflect.TypeOf(domain.DashboardRequest{}))
+
+// dashboardRequestV1 keeps 1.0.0's flat response schema registered
+// alongside the current version, so a Python producer that hasn't picked up
+// the oneOf discriminator yet can still validate against the shape it
+// actually emits via LookupVersion("dashboard_request", "1.0.0").
+var dashboardRequestV1 = Register[domain.DashboardRequest, domain.DashboardResponse](
+	"dashboard_request",
+	"1.0.0",
+	dashboardRequestSchema,
+	FromType(reflect.TypeOf(domain.DashboardResponse{})),
+)
+
+// DashboardRequest is the reference contract migrated from
+// domain.ContractValidator's hand-written ValidateDashboardRequestJSON /
+// ValidateDashboardResponseJSON pair: the schemas below are derived from the
+// same structs those methods validated, so TypedCall gets the same checks
+// for free, plus a contract_version header the Python side can negotiate on.
+//
+// 1.1.0 narrowed the response schema's status field into the oneOf above;
+// see dashboardRequestV1 for the version it superseded.
+var DashboardRequest = Register[domain.DashboardRequest, domain.DashboardResponse](
+	"dashboard_request",
+	"1.1.0",
+	dashboardRequestSchema,
+	dashboardResponseSchema,
+)
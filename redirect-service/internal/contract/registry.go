@@ -0,0 +1,194 @@
+// Package contract is a single source of truth for the RPC contracts
+// exchanged between redirect-service and the Python analytic-service: each
+// contract is registered once with its Go request/response types, a semver
+// version, and JSON Schemas, instead of hand-writing a validator pair per
+// RPC the way domain.ContractValidator did for dashboard_request.
+package contract
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Registration is one contract entry: everything TypedCall and the
+// contractcheck CLI need to validate a call and detect wire-format drift.
+type Registration struct {
+	Name           string
+	Version        string
+	RequestSchema  *Schema
+	ResponseSchema *Schema
+
+	reqType  reflect.Type
+	respType reflect.Type
+}
+
+// RequestType is the Go type TypedCall marshals as this contract's request.
+func (r *Registration) RequestType() reflect.Type { return r.reqType }
+
+// ResponseType is the Go type TypedCall unmarshals this contract's reply into.
+func (r *Registration) ResponseType() reflect.Type { return r.respType }
+
+var (
+	mu sync.RWMutex
+	// registrations holds, per name, the latest version registered -
+	// what Lookup and All hand to TypedCall and the contractcheck CLI by
+	// default.
+	registrations = make(map[string]*Registration)
+	// versions holds every version ever registered under a name, so a
+	// caller pinned to an older wire format (e.g. a Python producer that
+	// hasn't picked up a new DashboardResponse field yet) can still
+	// validate against it via LookupVersion after a newer version becomes
+	// the default.
+	versions = make(map[string]map[string]*Registration)
+)
+
+// Register records a contract under name and version, typed by Req/Resp.
+// Contracts are wired once at init time via package-level vars (see
+// dashboard.go); a second registration under the same name AND version
+// panics rather than silently overwriting the first, since that would hide
+// two packages racing to own the same contract. Registering a new version
+// of an existing name is expected - see LookupVersion - and becomes the
+// default returned by Lookup once it's the highest version on record.
+func Register[Req any, Resp any](name, version string, requestSchema, responseSchema *Schema) *Registration {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if byVersion, exists := versions[name]; exists {
+		if _, exists := byVersion[version]; exists {
+			panic(fmt.Sprintf("contract: %q version %q is already registered", name, version))
+		}
+	}
+
+	reg := &Registration{
+		Name:           name,
+		Version:        version,
+		RequestSchema:  requestSchema,
+		ResponseSchema: responseSchema,
+		reqType:        reflect.TypeOf((*Req)(nil)).Elem(),
+		respType:       reflect.TypeOf((*Resp)(nil)).Elem(),
+	}
+
+	if versions[name] == nil {
+		versions[name] = make(map[string]*Registration)
+	}
+	versions[name][version] = reg
+
+	if current, exists := registrations[name]; !exists || versionLess(current.Version, version) {
+		registrations[name] = reg
+	}
+
+	return reg
+}
+
+// Lookup returns the highest-versioned contract registered under name, if
+// any.
+func Lookup(name string) (*Registration, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	reg, ok := registrations[name]
+	return reg, ok
+}
+
+// LookupVersion returns the contract registered under name at exactly
+// version, regardless of whether a newer version has since become Lookup's
+// default.
+func LookupVersion(name, version string) (*Registration, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	byVersion, ok := versions[name]
+	if !ok {
+		return nil, false
+	}
+	reg, ok := byVersion[version]
+	return reg, ok
+}
+
+// All returns the latest version of every registered contract name, sorted
+// by name, for the contractcheck CLI to iterate over.
+func All() []*Registration {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	out := make([]*Registration, 0, len(registrations))
+	for _, reg := range registrations {
+		out = append(out, reg)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// versionLess reports whether a < b for dotted numeric versions like
+// "1.0.0". A non-numeric component compares as 0 rather than erroring,
+// since this only decides which registered version Lookup treats as
+// current.
+func versionLess(a, b string) bool {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var an, bn int
+		if i < len(as) {
+			an, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			bn, _ = strconv.Atoi(bs[i])
+		}
+		if an != bn {
+			return an < bn
+		}
+	}
+	return false
+}
+
+// TypeRegistration is a standalone struct's JSON Schema - a nested type
+// like domain.ClickEvent that the Python analytic-service needs the shape
+// of, but that isn't itself a request/response pair for an RPC (see
+// Registration).
+type TypeRegistration struct {
+	Name    string
+	Version string
+	Schema  *Schema
+}
+
+var typeRegistrations = make(map[string]*TypeRegistration)
+
+// RegisterType records a standalone type's schema under name. As with
+// Register, re-registering the same name panics rather than silently
+// overwriting it.
+func RegisterType(name, version string, schema *Schema) *TypeRegistration {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, exists := typeRegistrations[name]; exists {
+		panic(fmt.Sprintf("contract: type %q is already registered", name))
+	}
+
+	reg := &TypeRegistration{Name: name, Version: version, Schema: schema}
+	typeRegistrations[name] = reg
+	return reg
+}
+
+// LookupType returns the standalone type schema registered under name, if
+// any.
+func LookupType(name string) (*TypeRegistration, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	reg, ok := typeRegistrations[name]
+	return reg, ok
+}
+
+// AllTypes returns every registered standalone type schema, sorted by
+// name, for the contractcheck CLI to iterate over.
+func AllTypes() []*TypeRegistration {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	out := make([]*TypeRegistration, 0, len(typeRegistrations))
+	for _, reg := range typeRegistrations {
+		out = append(out, reg)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
@@ -0,0 +1,276 @@
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Schema is a small subset of JSON Schema (Draft 2020-12): enough to catch
+// the wire-format drift this package cares about (missing/renamed fields,
+// wrong JSON types, an enum value the other side doesn't expect, which of
+// several shapes a discriminated value must match) without pulling in a
+// full JSON Schema validator as a dependency.
+type Schema struct {
+	Type       string             `json:"type,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Items      *Schema            `json:"items,omitempty"`
+	Enum       []string           `json:"enum,omitempty"`
+	Format     string             `json:"format,omitempty"`
+	// OneOf holds alternative branch schemas for a discriminated value
+	// (see WithDiscriminator): v is valid only if it matches exactly one
+	// branch. When set, every other field on this Schema is ignored.
+	OneOf []*Schema `json:"oneOf,omitempty"`
+}
+
+// Validate checks data (a JSON document) against s, returning the first
+// mismatch found.
+func (s *Schema) Validate(data []byte) error {
+	if s == nil {
+		return nil
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("contract: invalid JSON: %w", err)
+	}
+	return s.validateValue(v, "$")
+}
+
+func (s *Schema) validateValue(v interface{}, path string) error {
+	if len(s.OneOf) > 0 {
+		return validateOneOf(s.OneOf, v, path)
+	}
+
+	switch s.Type {
+	case "object", "":
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected object, got %T", path, v)
+		}
+		for _, name := range s.Required {
+			if _, present := obj[name]; !present {
+				return fmt.Errorf("%s: missing required field %q", path, name)
+			}
+		}
+		for name, propSchema := range s.Properties {
+			val, present := obj[name]
+			if !present {
+				continue
+			}
+			if err := propSchema.validateValue(val, path+"."+name); err != nil {
+				return err
+			}
+		}
+
+	case "array":
+		arr, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected array, got %T", path, v)
+		}
+		if s.Items != nil {
+			for i, item := range arr {
+				if err := s.Items.validateValue(item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("%s: expected string, got %T", path, v)
+		}
+		if len(s.Enum) > 0 && !containsString(s.Enum, str) {
+			return fmt.Errorf("%s: value %q is not one of %v", path, str, s.Enum)
+		}
+
+	case "integer", "number":
+		if _, ok := v.(float64); !ok {
+			return fmt.Errorf("%s: expected number, got %T", path, v)
+		}
+
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("%s: expected boolean, got %T", path, v)
+		}
+	}
+
+	return nil
+}
+
+// validateOneOf enforces JSON Schema's oneOf semantics: v must validate
+// against exactly one of branches, not zero and not more than one.
+func validateOneOf(branches []*Schema, v interface{}, path string) error {
+	matches := 0
+	var lastErr error
+	for _, branch := range branches {
+		if err := branch.validateValue(v, path); err != nil {
+			lastErr = err
+			continue
+		}
+		matches++
+	}
+
+	switch matches {
+	case 1:
+		return nil
+	case 0:
+		return fmt.Errorf("%s: value did not match any oneOf branch: %w", path, lastErr)
+	default:
+		return fmt.Errorf("%s: value matched %d oneOf branches, want exactly 1", path, matches)
+	}
+}
+
+func containsString(haystack []string, needle string) bool {
+	for _, s := range haystack {
+		if s == needle {
+			return true
+		}
+	}
+	return false
+}
+
+// FromType derives a Schema from a Go struct type by reflection: field names
+// come from the json tag, a field is required unless its tag carries
+// omitempty or it's a pointer, and a `validate:"oneof=a b c"` tag (the
+// convention already used on domain.DashboardResponse.Status) becomes an
+// enum constraint.
+func FromType(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case reflect.TypeOf(time.Time{}):
+		// time.Time marshals to an RFC 3339 string, not the object its
+		// (all-unexported) fields would otherwise reflect as.
+		return &Schema{Type: "string", Format: "date-time"}
+	case reflect.TypeOf(primitive.ObjectID{}):
+		// primitive.ObjectID marshals to its hex string, not the
+		// [12]byte array it's defined as.
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" { // unexported
+				continue
+			}
+
+			jsonTag := field.Tag.Get("json")
+			if jsonTag == "-" {
+				continue
+			}
+			parts := strings.Split(jsonTag, ",")
+			name := parts[0]
+			if name == "" {
+				name = field.Name
+			}
+			omitempty := len(parts) > 1 && containsString(parts[1:], "omitempty")
+
+			fieldSchema := FromType(field.Type)
+			applyValidateTag(fieldSchema, field.Tag.Get("validate"))
+			s.Properties[name] = fieldSchema
+
+			if !omitempty && field.Type.Kind() != reflect.Ptr {
+				s.Required = append(s.Required, name)
+			}
+		}
+		sort.Strings(s.Required)
+		return s
+
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: FromType(t.Elem())}
+
+	case reflect.String:
+		return &Schema{Type: "string"}
+
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+
+	default:
+		return &Schema{Type: "object"}
+	}
+}
+
+// clone makes a shallow copy of s deep enough to mutate Properties[field]
+// and Required without disturbing the schema it was copied from: the
+// Properties map itself and the Required slice are copied, but unchanged
+// property schemas keep sharing their original pointers.
+func (s *Schema) clone() *Schema {
+	if s == nil {
+		return nil
+	}
+	c := *s
+	if s.Properties != nil {
+		c.Properties = make(map[string]*Schema, len(s.Properties))
+		for name, prop := range s.Properties {
+			c.Properties[name] = prop
+		}
+	}
+	if s.Required != nil {
+		c.Required = append([]string(nil), s.Required...)
+	}
+	return &c
+}
+
+// WithDiscriminator turns base (an object schema built by FromType) into a
+// oneOf of one branch per value discriminatorField can take, narrowing that
+// field to a single-value enum on each branch and adding that branch's
+// extraRequired fields to Required - e.g. domain.DashboardResponse's
+// Message is only required when Status is "error".
+func WithDiscriminator(base *Schema, discriminatorField string, extraRequired map[string][]string) *Schema {
+	values := make([]string, 0, len(extraRequired))
+	for value := range extraRequired {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	branches := make([]*Schema, 0, len(values))
+	for _, value := range values {
+		branch := base.clone()
+
+		discriminatorSchema := branch.Properties[discriminatorField].clone()
+		discriminatorSchema.Enum = []string{value}
+		branch.Properties[discriminatorField] = discriminatorSchema
+
+		required := append([]string{}, branch.Required...)
+		required = append(required, extraRequired[value]...)
+		sort.Strings(required)
+		branch.Required = required
+
+		branches = append(branches, branch)
+	}
+
+	return &Schema{OneOf: branches}
+}
+
+// applyValidateTag reads a go-playground/validator-style tag (the same
+// convention domain.DashboardRequest/DashboardResponse already use) for the
+// one piece of it that maps onto JSON Schema: oneof=... becomes an enum.
+func applyValidateTag(s *Schema, tag string) {
+	if tag == "" {
+		return
+	}
+	for _, rule := range strings.Split(tag, ",") {
+		if strings.HasPrefix(rule, "oneof=") {
+			s.Enum = strings.Fields(strings.TrimPrefix(rule, "oneof="))
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package contract
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type testAddress struct {
+	City string `json:"city"`
+}
+
+type testPerson struct {
+	Name    string       `json:"name"`
+	Age     int          `json:"age"`
+	Role    string       `json:"role" validate:"required,oneof=admin member"`
+	Address *testAddress `json:"address,omitempty"`
+}
+
+func TestFromType_RequiredAndOmitempty(t *testing.T) {
+	s := FromType(reflect.TypeOf(testPerson{}))
+
+	want := []string{"age", "name", "role"} // sorted, address excluded (omitempty)
+	if !reflect.DeepEqual(s.Required, want) {
+		t.Errorf("Required = %v, want %v", s.Required, want)
+	}
+	if s.Properties["role"].Enum == nil || s.Properties["role"].Enum[0] != "admin" {
+		t.Errorf("expected role's oneof tag to populate Enum, got %v", s.Properties["role"].Enum)
+	}
+}
+
+func TestSchema_Validate(t *testing.T) {
+	s := FromType(reflect.TypeOf(testPerson{}))
+
+	tests := []struct {
+		name    string
+		json    string
+		wantErr bool
+	}{
+		{"valid document", `{"name":"Ada","age":30,"role":"admin"}`, false},
+		{"missing required field", `{"name":"Ada","role":"admin"}`, true},
+		{"wrong type for age", `{"name":"Ada","age":"thirty","role":"admin"}`, true},
+		{"value not in enum", `{"name":"Ada","age":30,"role":"guest"}`, true},
+		{"not a JSON object", `"just a string"`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.Validate([]byte(tt.json))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate(%s) error = %v, wantErr %v", tt.json, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegistry_LookupAndDuplicateRegistration(t *testing.T) {
+	type req struct {
+		Value string `json:"value"`
+	}
+	type resp struct {
+		OK bool `json:"ok"`
+	}
+
+	Register[req, resp]("test_contract_lookup", "1.0.0", FromType(reflect.TypeOf(req{})), FromType(reflect.TypeOf(resp{})))
+
+	reg, ok := Lookup("test_contract_lookup")
+	if !ok {
+		t.Fatal("expected the registered contract to be found")
+	}
+	if reg.Version != "1.0.0" {
+		t.Errorf("Version = %q, want %q", reg.Version, "1.0.0")
+	}
+	if reg.RequestType() != reflect.TypeOf(req{}) {
+		t.Errorf("RequestType = %v, want %v", reg.RequestType(), reflect.TypeOf(req{}))
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected re-registering the same contract name to panic")
+		}
+	}()
+	Register[req, resp]("test_contract_lookup", "1.0.0", nil, nil)
+}
+
+func TestDashboardRequestContract_MatchesExportedSchema(t *testing.T) {
+	// Guards against the reference contract's schema drifting without a
+	// version bump, the same thing the contractcheck CLI checks in CI.
+	if DashboardRequest.Version == "" {
+		t.Fatal("expected the dashboard_request contract to carry a version")
+	}
+
+	data, err := os.ReadFile(filepath.Join("..", "..", "contracts", "dashboard_request.json"))
+	if err != nil {
+		t.Fatalf("read exported schema: %v", err)
+	}
+	var exported struct {
+		Version  string  `json:"version"`
+		Request  *Schema `json:"request"`
+		Response *Schema `json:"response"`
+	}
+	if err := json.Unmarshal(data, &exported); err != nil {
+		t.Fatalf("unmarshal exported schema: %v", err)
+	}
+	sameSchemas := reflect.DeepEqual(exported.Request, DashboardRequest.RequestSchema) &&
+		reflect.DeepEqual(exported.Response, DashboardRequest.ResponseSchema)
+	if !sameSchemas && exported.Version == DashboardRequest.Version {
+		t.Errorf("dashboard_request schema changed but its version is still %s", DashboardRequest.Version)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"user_id": 1})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if err := DashboardRequest.RequestSchema.Validate(body); err != nil {
+		t.Errorf("expected a valid dashboard request to pass schema validation, got %v", err)
+	}
+}
+
+func TestDashboardResponseSchema_MessageOnlyRequiredOnError(t *testing.T) {
+	base := map[string]interface{}{
+		"user_id":       1,
+		"total_clicks":  0,
+		"total_links":   0,
+		"uniq_visitors": 0,
+		"top_links":     []interface{}{},
+		"stat_links":    []interface{}{},
+	}
+
+	withStatus := func(status string, message string) []byte {
+		doc := map[string]interface{}{}
+		for k, v := range base {
+			doc[k] = v
+		}
+		doc["status"] = status
+		if message != "" {
+			doc["message"] = message
+		}
+		data, _ := json.Marshal(doc)
+		return data
+	}
+
+	if err := dashboardResponseSchema.Validate(withStatus("success", "")); err != nil {
+		t.Errorf("expected a success response without a message to pass, got %v", err)
+	}
+	if err := dashboardResponseSchema.Validate(withStatus("error", "")); err == nil {
+		t.Error("expected an error response without a message to fail")
+	}
+	if err := dashboardResponseSchema.Validate(withStatus("error", "boom")); err != nil {
+		t.Errorf("expected an error response with a message to pass, got %v", err)
+	}
+}
+
+func TestRegistry_VersionedLookup(t *testing.T) {
+	type reqV1 struct {
+		Value string `json:"value"`
+	}
+	type respV1 struct {
+		OK bool `json:"ok"`
+	}
+
+	Register[reqV1, respV1]("test_contract_versioned", "1.0.0", FromType(reflect.TypeOf(reqV1{})), FromType(reflect.TypeOf(respV1{})))
+	Register[reqV1, respV1]("test_contract_versioned", "1.1.0", FromType(reflect.TypeOf(reqV1{})), FromType(reflect.TypeOf(respV1{})))
+
+	latest, ok := Lookup("test_contract_versioned")
+	if !ok || latest.Version != "1.1.0" {
+		t.Fatalf("Lookup() = %+v, %v, want version 1.1.0", latest, ok)
+	}
+
+	v1, ok := LookupVersion("test_contract_versioned", "1.0.0")
+	if !ok || v1.Version != "1.0.0" {
+		t.Fatalf("LookupVersion(..., \"1.0.0\") = %+v, %v, want version 1.0.0", v1, ok)
+	}
+}
+
+func TestRegisterType_LookupAndDuplicate(t *testing.T) {
+	type testWidget struct {
+		Name string `json:"name"`
+	}
+
+	RegisterType("test_widget", "1.0.0", FromType(reflect.TypeOf(testWidget{})))
+
+	reg, ok := LookupType("test_widget")
+	if !ok {
+		t.Fatal("expected the registered type to be found")
+	}
+	if reg.Version != "1.0.0" {
+		t.Errorf("Version = %q, want %q", reg.Version, "1.0.0")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected re-registering the same type name to panic")
+		}
+	}()
+	RegisterType("test_widget", "1.0.0", nil)
+}
@@ -0,0 +1,19 @@
+package contract
+
+import (
+	"reflect"
+
+	"github.com/hoggir/re-path/redirect-service/internal/domain"
+)
+
+// Standalone type schemas: domain types the Python analytic-service needs
+// the shape of even though they aren't a request/response pair for an RPC
+// (see dashboard.go's Registration). Exported to schemas/<name>.schema.json
+// by the contractcheck CLI.
+var (
+	ClickEventSchema = RegisterType("click_event", "1.0.0", FromType(reflect.TypeOf(domain.ClickEvent{})))
+
+	RecentClickSchema = RegisterType("recent_click", "1.0.0", FromType(reflect.TypeOf(domain.RecentClick{})))
+
+	TopLinkSchema = RegisterType("top_link", "1.0.0", FromType(reflect.TypeOf(domain.TopLink{})))
+)
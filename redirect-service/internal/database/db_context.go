@@ -0,0 +1,64 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/hoggir/re-path/redirect-service/internal/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// slowQueryThreshold marks a query as "slow" for the purposes of the counter
+// below; the duration itself is always recorded in the histogram regardless.
+const slowQueryThreshold = 200 * time.Millisecond
+
+var (
+	mongoQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "repath_mongo_query_duration_seconds",
+		Help:    "MongoDB query duration by operation kind.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"operation"})
+
+	mongoSlowQueries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "repath_mongo_slow_queries_total",
+		Help: "MongoDB queries that exceeded the slow-query threshold, by operation kind.",
+	}, []string{"operation"})
+)
+
+// DBContext derives request-scoped contexts carrying per-operation deadlines,
+// so a single slow read can't eat the timeout budget meant for a write (or
+// vice versa), and reports how long each operation actually took.
+type DBContext struct {
+	cfg *config.Config
+}
+
+func NewDBContext(cfg *config.Config) *DBContext {
+	return &DBContext{cfg: cfg}
+}
+
+// Read derives a context bounded by MongoDB.ReadTimeout.
+func (d *DBContext) Read(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, d.cfg.MongoDB.ReadTimeout)
+}
+
+// Write derives a context bounded by MongoDB.WriteTimeout.
+func (d *DBContext) Write(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, d.cfg.MongoDB.WriteTimeout)
+}
+
+// Aggregate derives a context bounded by MongoDB.AggregateTimeout.
+func (d *DBContext) Aggregate(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, d.cfg.MongoDB.AggregateTimeout)
+}
+
+// Observe records how long operation took and bumps the slow-query counter if
+// it crossed slowQueryThreshold. Call with `defer` right after deriving the
+// context: `defer db.Observe("FindByShortCode", time.Now())`.
+func (d *DBContext) Observe(operation string, start time.Time) {
+	elapsed := time.Since(start)
+	mongoQueryDuration.WithLabelValues(operation).Observe(elapsed.Seconds())
+	if elapsed >= slowQueryThreshold {
+		mongoSlowQueries.WithLabelValues(operation).Inc()
+	}
+}
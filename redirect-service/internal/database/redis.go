@@ -3,43 +3,43 @@ package database
 import (
 	"context"
 	"fmt"
-	"time"
+	"net"
 
 	"github.com/hoggir/re-path/redirect-service/internal/config"
 	"github.com/hoggir/re-path/redirect-service/internal/logger"
-	"github.com/redis/go-redis/v9"
+	"github.com/redis/rueidis"
 )
 
 type Redis struct {
-	Client *redis.Client
+	Client rueidis.Client
 	logger logger.Logger
 }
 
 func NewRedis(cfg *config.Config, log logger.Logger) (*Redis, error) {
+	client, err := rueidis.NewClient(rueidis.ClientOption{
+		InitAddress: []string{fmt.Sprintf("%s:%s", cfg.Redis.Host, cfg.Redis.Port)},
+		Password:    cfg.Redis.Password,
+		SelectDB:    cfg.Redis.DB,
+		Dialer: net.Dialer{
+			Timeout: cfg.Redis.ConnTimeout,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), cfg.Redis.ConnTimeout)
 	defer cancel()
 
-	client := redis.NewClient(&redis.Options{
-		Addr:         fmt.Sprintf("%s:%s", cfg.Redis.Host, cfg.Redis.Port),
-		Password:     cfg.Redis.Password,
-		DB:           cfg.Redis.DB,
-		PoolSize:     cfg.Redis.PoolSize,
-		MinIdleConns: cfg.Redis.MinIdleConns,
-		MaxRetries:   cfg.Redis.MaxRetries,
-		DialTimeout:  cfg.Redis.ConnTimeout,
-		ReadTimeout:  3 * time.Second,
-		WriteTimeout: 3 * time.Second,
-	})
-
-	if err := client.Ping(ctx).Err(); err != nil {
+	if err := client.Do(ctx, client.B().Ping().Build()).Error(); err != nil {
+		client.Close()
 		return nil, fmt.Errorf("failed to connect to redis: %w", err)
 	}
 
 	log.Info("Redis connected successfully",
 		"host", cfg.Redis.Host,
 		"port", cfg.Redis.Port,
-		"poolSize", cfg.Redis.PoolSize,
-		"minIdleConns", cfg.Redis.MinIdleConns)
+		"localCacheTTL", cfg.Redis.LocalCacheTTL)
 
 	return &Redis{
 		Client: client,
@@ -49,10 +49,7 @@ func NewRedis(cfg *config.Config, log logger.Logger) (*Redis, error) {
 
 func (r *Redis) Close() error {
 	r.logger.Info("closing Redis connection")
-	if err := r.Client.Close(); err != nil {
-		return fmt.Errorf("failed to close redis connection: %w", err)
-	}
-
+	r.Client.Close()
 	r.logger.Info("Redis connection closed successfully")
 	return nil
 }
@@ -0,0 +1,172 @@
+package domain
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/language"
+)
+
+// embeddedLocales holds the redirect-service's own translations. Each file
+// is named <BCP-47 tag>.json (e.g. "en.json", "id.json") and maps an
+// AppError.Code to a message template; {0}, {1}, ... are replaced by the
+// positional args attached via AppError.WithArgs.
+//
+//go:embed locales/*.json
+var embeddedLocales embed.FS
+
+var defaultLanguage = language.English
+
+var (
+	catalogMu     sync.RWMutex
+	catalog       = map[language.Tag]map[string]string{}
+	supportedTags []language.Tag
+	matcher       language.Matcher
+)
+
+func init() {
+	if err := loadCatalogFS(embeddedLocales, "locales"); err != nil {
+		panic("domain: failed to load embedded error catalog: " + err.Error())
+	}
+}
+
+// RegisterErrorCatalog merges translation bundles from fsys into the global
+// error catalog, so downstream apps can add new locales or override existing
+// messages without editing this module. Files are read from the root of
+// fsys using the same "<tag>.json" naming as the embedded bundles; a tag
+// already present is merged code-by-code, with fsys taking precedence.
+func RegisterErrorCatalog(fsys fs.FS) error {
+	return loadCatalogFS(fsys, ".")
+}
+
+func loadCatalogFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		if !strings.HasSuffix(name, ".json") {
+			continue
+		}
+
+		tagName := strings.TrimSuffix(name, ".json")
+		tag, err := language.Parse(tagName)
+		if err != nil {
+			return fmt.Errorf("error catalog: %s: invalid locale tag %q: %w", name, tagName, err)
+		}
+
+		data, err := fs.ReadFile(fsys, path.Join(dir, name))
+		if err != nil {
+			return fmt.Errorf("error catalog: %s: %w", name, err)
+		}
+
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return fmt.Errorf("error catalog: %s: %w", name, err)
+		}
+
+		if catalog[tag] == nil {
+			catalog[tag] = make(map[string]string, len(messages))
+		}
+		for code, tmpl := range messages {
+			catalog[tag][code] = tmpl
+		}
+	}
+
+	supportedTags = supportedTags[:0]
+	for tag := range catalog {
+		supportedTags = append(supportedTags, tag)
+	}
+	matcher = language.NewMatcher(supportedTags)
+
+	return nil
+}
+
+// MatchLanguage negotiates the best supported locale for an HTTP
+// Accept-Language header value, falling back to defaultLanguage when the
+// header is empty, unparsable, or matches nothing in the catalog.
+func MatchLanguage(acceptLanguage string) language.Tag {
+	if acceptLanguage == "" {
+		return defaultLanguage
+	}
+
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return defaultLanguage
+	}
+
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+	if matcher == nil {
+		return defaultLanguage
+	}
+
+	// Return the supported tag itself rather than Match's result tag, which
+	// can carry a -u-rg- extension copied from a regional request (e.g.
+	// "id-ID" matching "id").
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return defaultLanguage
+	}
+	return supportedTags[index]
+}
+
+func catalogLookup(tag language.Tag, code string) (string, bool) {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+
+	if tmpl, ok := catalog[tag][code]; ok {
+		return tmpl, true
+	}
+	// Fall back from a region-qualified tag (e.g. "en-GB") to its base
+	// language ("en") before giving up.
+	if base, conf := tag.Base(); conf != language.No {
+		if tmpl, ok := catalog[language.Make(base.String())][code]; ok {
+			return tmpl, true
+		}
+	}
+	return "", false
+}
+
+// formatTemplate replaces {0}, {1}, ... in tmpl with args, left untouched
+// when there are no args or no matching placeholder.
+func formatTemplate(tmpl string, args []interface{}) string {
+	if len(args) == 0 {
+		return tmpl
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(tmpl); i++ {
+		if tmpl[i] != '{' {
+			b.WriteByte(tmpl[i])
+			continue
+		}
+		end := strings.IndexByte(tmpl[i:], '}')
+		if end == -1 {
+			b.WriteString(tmpl[i:])
+			break
+		}
+		end += i
+		if idx, err := strconv.Atoi(tmpl[i+1 : end]); err == nil && idx >= 0 && idx < len(args) {
+			fmt.Fprintf(&b, "%v", args[idx])
+			i = end
+			continue
+		}
+		b.WriteByte(tmpl[i])
+	}
+	return b.String()
+}
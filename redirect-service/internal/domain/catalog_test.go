@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestAppError_LocalizedMessage_IndonesianFallback(t *testing.T) {
+	tag := MatchLanguage("id-ID,id;q=0.9,en;q=0.8")
+	if tag != language.Indonesian {
+		t.Fatalf("MatchLanguage() = %v, want %v", tag, language.Indonesian)
+	}
+
+	got := ErrURLNotFound.LocalizedMessage(tag)
+	want := "URL pendek yang Anda cari tidak ditemukan"
+	if got != want {
+		t.Errorf("LocalizedMessage(id) = %q, want %q", got, want)
+	}
+}
+
+func TestAppError_LocalizedMessage_UnknownLocaleFallsBackToDefault(t *testing.T) {
+	tag := MatchLanguage("fr-FR")
+
+	got := ErrURLNotFound.LocalizedMessage(tag)
+	if got != ErrURLNotFound.Message {
+		t.Errorf("LocalizedMessage(unsupported) = %q, want default Message %q", got, ErrURLNotFound.Message)
+	}
+}
+
+func TestAppError_LocalizedMessage_ArgSubstitution(t *testing.T) {
+	err := ErrURLExpired.WithArgs("abc123", "2026-01-01T00:00:00Z")
+
+	en := err.LocalizedMessage(language.English)
+	wantEn := "Short URL abc123 expired at 2026-01-01T00:00:00Z"
+	if en != wantEn {
+		t.Errorf("LocalizedMessage(en) = %q, want %q", en, wantEn)
+	}
+
+	id := err.LocalizedMessage(language.Indonesian)
+	wantID := "URL pendek abc123 telah kedaluwarsa pada 2026-01-01T00:00:00Z"
+	if id != wantID {
+		t.Errorf("LocalizedMessage(id) = %q, want %q", id, wantID)
+	}
+}
+
+func TestAppError_LocalizedMessage_NoArgsLeavesPlaceholders(t *testing.T) {
+	got := ErrURLExpired.LocalizedMessage(language.English)
+	want := "Short URL {0} expired at {1}"
+	if got != want {
+		t.Errorf("LocalizedMessage() without args = %q, want %q", got, want)
+	}
+}
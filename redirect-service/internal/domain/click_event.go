@@ -25,12 +25,44 @@ type ClickEvent struct {
 	Lat            float64            `bson:"lat,omitempty" json:"lat,omitempty"`
 	Lon            float64            `bson:"lon,omitempty" json:"lon,omitempty"`
 	IsBot          bool               `bson:"isBot" json:"isBot"`
+	BotScore       float64            `bson:"botScore,omitempty" json:"botScore,omitempty"`
+	BotReasons     []string           `bson:"botReasons,omitempty" json:"botReasons,omitempty"`
 }
 
 func (ClickEvent) CollectionName() string {
 	return "click_events"
 }
 
+// BotDetectionResult is BotDetector's verdict for one click: a weighted
+// confidence score built from every signal that fired, rather than a
+// single boolean, so analytic-service can filter or down-weight traffic
+// instead of only dropping it.
+type BotDetectionResult struct {
+	IsBot      bool
+	Confidence float64
+	Reasons    []string
+}
+
+// ClickMetadata is the raw signal captured on the redirect hot path: only
+// what's available straight off the incoming HTTP request, before any UA
+// parsing, GeoIP lookup, or IP hashing.
+type ClickMetadata struct {
+	ClientIP  string `json:"clientIp"`
+	UserAgent string `json:"userAgent"`
+	Referrer  string `json:"referrer"`
+}
+
+// ClickEventMessage is the payload ClickEventService publishes onto the
+// click_events queue: ClickMetadata plus the short code it was captured
+// against and when. ClickEventConsumer does the enrichment (UA parse,
+// GeoIP, IP hash, domain extraction) this used to do synchronously on the
+// redirect path, turning it into a ClickEvent before it's persisted.
+type ClickEventMessage struct {
+	ClickMetadata
+	ShortCode string    `json:"shortCode"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
 // PayloadElasticClick represents the structure for sending click event data to Elasticsearch
 type PayloadElasticClick struct {
 	IndexType string    `json:"index_type"`
@@ -50,6 +50,26 @@ type DashboardResponse struct {
 	Message      *string    `json:"message,omitempty"`
 }
 
+// Validate mirrors the struct's validate tags by hand (status must be one of
+// the three known values, and the two counters can't go negative), since
+// DashboardResponse is decoded from analytic-service's reply rather than
+// bound from an HTTP request and so never runs through the gin binder that
+// would otherwise enforce those tags.
+func (r *DashboardResponse) Validate() error {
+	switch r.Status {
+	case "success", "error", "limited":
+	default:
+		return errors.New("status must be one of: success, error, limited")
+	}
+	if r.TotalClicks < 0 {
+		return errors.New("total_clicks must be greater than or equal to 0")
+	}
+	if r.TotalLinks < 0 {
+		return errors.New("total_links must be greater than or equal to 0")
+	}
+	return nil
+}
+
 func (r *DashboardResponse) IsSuccess() bool {
 	return r.Status == "success"
 }
@@ -3,6 +3,8 @@ package domain
 import (
 	"fmt"
 	"net/http"
+
+	"golang.org/x/text/language"
 )
 
 type AppError struct {
@@ -12,6 +14,10 @@ type AppError struct {
 	HTTPStatus int
 	Metadata   map[string]interface{}
 	Err        error
+	// Args are positional substitutions for {0}, {1}, ... placeholders in
+	// Message and in any translated template the error catalog resolves for
+	// Code. Set via WithArgs; left nil, Message/templates are used as-is.
+	Args []interface{}
 }
 
 func (e *AppError) Error() string {
@@ -46,6 +52,27 @@ func (e *AppError) WithMessage(msg string) *AppError {
 	return &newErr
 }
 
+// WithArgs attaches positional arguments for the {0}, {1}, ... placeholders
+// in Message and in the catalog template matched by Code, e.g.
+// ErrURLExpired.WithArgs(shortCode, expiredAt).LocalizedMessage(tag).
+func (e *AppError) WithArgs(args ...interface{}) *AppError {
+	newErr := *e
+	newErr.Args = args
+	return &newErr
+}
+
+// LocalizedMessage renders Message in tag's locale: it looks up Code in the
+// error catalog (see RegisterErrorCatalog), substitutes Args into whichever
+// template it finds, and falls back to the untranslated Message when tag has
+// no catalog entry for Code. Internal is never translated; it stays English
+// for logs regardless of tag.
+func (e *AppError) LocalizedMessage(tag language.Tag) string {
+	if tmpl, ok := catalogLookup(tag, e.Code); ok {
+		return formatTemplate(tmpl, e.Args)
+	}
+	return formatTemplate(e.Message, e.Args)
+}
+
 var (
 	ErrURLNotFound = &AppError{
 		Code:       "URL_NOT_FOUND",
@@ -54,9 +81,11 @@ var (
 		HTTPStatus: http.StatusNotFound,
 	}
 
+	// ErrURLExpired takes two WithArgs: the short code and its expiry
+	// timestamp, substituted into the {0}/{1} placeholders below.
 	ErrURLExpired = &AppError{
 		Code:       "URL_EXPIRED",
-		Message:    "This short URL has expired",
+		Message:    "Short URL {0} expired at {1}",
 		Internal:   "url expiration date has passed",
 		HTTPStatus: http.StatusGone,
 	}
@@ -104,6 +133,23 @@ var (
 		Internal:   "insufficient permissions",
 		HTTPStatus: http.StatusForbidden,
 	}
+
+	// ErrTokenRevoked covers both revocation paths: the token's own jti was
+	// explicitly revoked (e.g. by logout), or its token_version claim is
+	// behind the user's current counter (a session-wide invalidation).
+	ErrTokenRevoked = &AppError{
+		Code:       "TOKEN_REVOKED",
+		Message:    "Your session has been revoked. Please log in again",
+		Internal:   "jwt token revoked or superseded by a newer token_version",
+		HTTPStatus: http.StatusUnauthorized,
+	}
+
+	ErrRefreshTokenInvalid = &AppError{
+		Code:       "REFRESH_TOKEN_INVALID",
+		Message:    "Your session has expired. Please log in again",
+		Internal:   "refresh token not found, expired, or already rotated past its grace window",
+		HTTPStatus: http.StatusUnauthorized,
+	}
 )
 
 var (
@@ -0,0 +1,19 @@
+package domain
+
+// GeoLocation is the normalized result of a GeoIP lookup, regardless of which
+// Provider served it.
+type GeoLocation struct {
+	Country     string  `json:"country"`
+	CountryCode string  `json:"countryCode"`
+	Region      string  `json:"region,omitempty"`
+	RegionName  string  `json:"regionName,omitempty"`
+	City        string  `json:"city,omitempty"`
+	Zip         string  `json:"zip,omitempty"`
+	Lat         float64 `json:"lat,omitempty"`
+	Lon         float64 `json:"lon,omitempty"`
+	Timezone    string  `json:"timezone,omitempty"`
+	ISP         string  `json:"isp,omitempty"`
+	Org         string  `json:"org,omitempty"`
+	AS          string  `json:"as,omitempty"`
+	Query       string  `json:"query,omitempty"`
+}
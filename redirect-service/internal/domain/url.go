@@ -33,6 +33,13 @@ type FindByShortCode struct {
 	ExpiresAt   *time.Time `bson:"expiresAt,omitempty" json:"expiresAt,omitempty"`
 }
 
+// ExpiredURLRef is the minimal projection the sweeper needs to act on a URL
+// whose expiresAt has passed.
+type ExpiredURLRef struct {
+	ShortCode string `bson:"shortCode" json:"shortCode"`
+	UserID    int    `bson:"userId" json:"userId"`
+}
+
 func (URL) CollectionName() string {
 	return "urls"
 }
@@ -0,0 +1,7 @@
+package dto
+
+import "github.com/hoggir/re-path/redirect-service/internal/service"
+
+type SweepResponse struct {
+	Results []service.SweepResult `json:"results"`
+}
@@ -0,0 +1,16 @@
+package dto
+
+// RefreshRequest is the body of POST /auth/refresh.
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// TokenPairResponse is the access+refresh pair handed back by
+// POST /auth/refresh.
+type TokenPairResponse struct {
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	TokenType        string `json:"token_type" example:"Bearer"`
+	ExpiresIn        int64  `json:"expires_in" example:"3600"`
+	RefreshExpiresIn int64  `json:"refresh_expires_in" example:"1209600"`
+}
@@ -47,12 +47,15 @@ func HandleError(c *gin.Context, err error) {
 	var appErr *domain.AppError
 
 	if errors.As(err, &appErr) {
+		tag := domain.MatchLanguage(c.GetHeader("Accept-Language"))
+		message := appErr.LocalizedMessage(tag)
+
 		c.JSON(appErr.HTTPStatus, Response{
 			Success: false,
-			Message: appErr.Message,
+			Message: message,
 			Error: &ErrorDetail{
 				Code:     appErr.Code,
-				Message:  appErr.Message,
+				Message:  message,
 				Metadata: appErr.Metadata,
 			},
 			Timestamp: time.Now(),
@@ -0,0 +1,83 @@
+// Package eventbus abstracts the click-event and dashboard-RPC transport
+// behind one interface, so the driver backing them - RabbitMQ or Kafka - is
+// a config knob (EVENTBUS_DRIVER) rather than a code change. See
+// config.EventBusConfig.
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hoggir/re-path/redirect-service/internal/config"
+	"github.com/hoggir/re-path/redirect-service/internal/database"
+	"github.com/hoggir/re-path/redirect-service/internal/logger"
+)
+
+// Driver selects which broker backs an EventBus.
+type Driver string
+
+const (
+	DriverRabbitMQ Driver = "rabbitmq"
+	DriverKafka    Driver = "kafka"
+)
+
+// Delivery is one message handed to a Subscribe consumer. Ack/Nack mirror
+// amqp.Delivery's acknowledgement model regardless of which backend produced
+// the message - see the Nack doc comment on the Kafka implementation for
+// where that model leaks through.
+type Delivery struct {
+	Body    []byte
+	Headers map[string]string
+
+	ack  func() error
+	nack func(requeue bool) error
+}
+
+// Ack confirms the message was processed; the backend won't redeliver it.
+func (d Delivery) Ack() error { return d.ack() }
+
+// Nack reports the message was not processed. requeue asks the backend to
+// redeliver it (RabbitMQ: requeues onto the same queue; Kafka: simply
+// doesn't commit the offset, so it's redelivered on the consumer group's
+// next rebalance or restart). false drops it the same way an unrecoverable
+// message would be handled by the caller's own retry/DLQ logic.
+func (d Delivery) Nack(requeue bool) error { return d.nack(requeue) }
+
+// EventBus is the transport-agnostic surface the click-event pipeline and
+// the dashboard RPC call are built against.
+type EventBus interface {
+	// Publish fire-and-forgets payload onto topic (RabbitMQ: a queue name;
+	// Kafka: a topic name), returning once the broker has durably accepted
+	// it.
+	Publish(ctx context.Context, topic string, payload []byte) error
+
+	// Call performs a request/response round trip against topic, waiting up
+	// to timeout for a reply demultiplexed onto this EventBus instance by
+	// correlation ID.
+	Call(ctx context.Context, topic string, payload []byte, timeout time.Duration) ([]byte, error)
+
+	// Subscribe starts consuming topic as groupID and streams deliveries on
+	// the returned channel until ctx is done (on the Kafka backend, Close
+	// also stops it; the RabbitMQ backend's Close does not).
+	Subscribe(ctx context.Context, topic, groupID string) (<-chan Delivery, error)
+
+	// Close releases the resources this EventBus opened for itself. It does
+	// not close connections it was handed (e.g. *database.RabbitMQ), which
+	// remain owned by whoever constructed them.
+	Close() error
+}
+
+// New builds the EventBus selected by cfg.EventBus.Driver. rabbitmq is
+// reused as-is (it's already connected by the time wiring reaches here);
+// the Kafka backend dials cfg.Kafka.Brokers itself.
+func New(cfg *config.Config, rabbitmq *database.RabbitMQ, log logger.Logger) (EventBus, error) {
+	switch Driver(cfg.EventBus.Driver) {
+	case DriverKafka:
+		return newKafkaBus(cfg, log)
+	case DriverRabbitMQ, "":
+		return newRabbitMQBus(rabbitmq, log)
+	default:
+		return nil, fmt.Errorf("eventbus: unknown driver %q", cfg.EventBus.Driver)
+	}
+}
@@ -0,0 +1,209 @@
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hoggir/re-path/redirect-service/internal/config"
+	"github.com/hoggir/re-path/redirect-service/internal/domain"
+	"github.com/hoggir/re-path/redirect-service/internal/logger"
+	kafka "github.com/segmentio/kafka-go"
+)
+
+// kafkaBus adapts segmentio/kafka-go to EventBus. Publish and Call share one
+// kafka.Writer; Call demultiplexes replies off a reply topic unique to this
+// process (replyTopic), so multiple redirect-service replicas calling the
+// same request topic never see each other's responses.
+type kafkaBus struct {
+	cfg    *config.Config
+	logger logger.Logger
+
+	writer     *kafka.Writer
+	replyTopic string
+
+	replyOnce   sync.Once
+	replyReader *kafka.Reader
+
+	mu      sync.Mutex
+	pending map[string]chan kafka.Message
+
+	readersMu sync.Mutex
+	readers   []*kafka.Reader
+}
+
+func newKafkaBus(cfg *config.Config, log logger.Logger) (EventBus, error) {
+	if len(cfg.Kafka.Brokers) == 0 {
+		return nil, fmt.Errorf("eventbus: kafka driver selected but KAFKA_BROKERS is empty")
+	}
+
+	return &kafkaBus{
+		cfg:    cfg,
+		logger: log,
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(cfg.Kafka.Brokers...),
+			Balancer:     &kafka.LeastBytes{},
+			RequiredAcks: kafka.RequireAll,
+		},
+		replyTopic: fmt.Sprintf("%s.%s", cfg.Kafka.ReplyTopicPrefix, uuid.New().String()),
+		pending:    make(map[string]chan kafka.Message),
+	}, nil
+}
+
+func (b *kafkaBus) Publish(ctx context.Context, topic string, payload []byte) error {
+	err := b.writer.WriteMessages(ctx, kafka.Message{Topic: topic, Value: payload})
+	if err != nil {
+		return domain.ErrQueueError.WithContext("topic", topic).WithContext("operation", "WriteMessages").Wrap(err)
+	}
+	return nil
+}
+
+func (b *kafkaBus) Call(ctx context.Context, topic string, payload []byte, timeout time.Duration) ([]byte, error) {
+	b.replyOnce.Do(func() { go b.consumeReplies() })
+
+	correlationID := uuid.New().String()
+	replyCh := make(chan kafka.Message, 1)
+	b.mu.Lock()
+	b.pending[correlationID] = replyCh
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		delete(b.pending, correlationID)
+		b.mu.Unlock()
+	}()
+
+	err := b.writer.WriteMessages(ctx, kafka.Message{
+		Topic: topic,
+		Value: payload,
+		Headers: []kafka.Header{
+			{Key: "correlation_id", Value: []byte(correlationID)},
+			{Key: "reply_topic", Value: []byte(b.replyTopic)},
+		},
+	})
+	if err != nil {
+		return nil, domain.ErrQueueError.WithContext("topic", topic).WithContext("operation", "WriteMessages").Wrap(err)
+	}
+
+	select {
+	case msg := <-replyCh:
+		return msg.Value, nil
+	case <-time.After(timeout):
+		return nil, domain.ErrTimeout.WithContext("topic", topic).WithContext("correlationId", correlationID)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// consumeReplies runs for the lifetime of the bus, reading replyTopic -
+// which no other instance's reader is subscribed to - and dispatching each
+// message to the channel registered under its correlation_id header.
+func (b *kafkaBus) consumeReplies() {
+	b.replyReader = kafka.NewReader(kafka.ReaderConfig{
+		Brokers: b.cfg.Kafka.Brokers,
+		Topic:   b.replyTopic,
+	})
+
+	for {
+		msg, err := b.replyReader.ReadMessage(context.Background())
+		if err != nil {
+			b.logger.Error("eventbus: kafka reply reader stopped", "topic", b.replyTopic, "error", err)
+			return
+		}
+
+		correlationID := headerValue(msg.Headers, "correlation_id")
+		if correlationID == "" {
+			continue
+		}
+
+		b.mu.Lock()
+		ch, ok := b.pending[correlationID]
+		b.mu.Unlock()
+		if ok {
+			ch <- msg
+		}
+	}
+}
+
+func (b *kafkaBus) Subscribe(ctx context.Context, topic, groupID string) (<-chan Delivery, error) {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: b.cfg.Kafka.Brokers,
+		Topic:   topic,
+		GroupID: groupID,
+	})
+
+	b.readersMu.Lock()
+	b.readers = append(b.readers, reader)
+	b.readersMu.Unlock()
+
+	out := make(chan Delivery)
+	go func() {
+		defer close(out)
+		for {
+			msg, err := reader.FetchMessage(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				b.logger.Error("eventbus: kafka fetch failed", "topic", topic, "group", groupID, "error", err)
+				return
+			}
+			out <- toKafkaDelivery(reader, msg)
+		}
+	}()
+
+	return out, nil
+}
+
+// toKafkaDelivery wraps msg so Ack commits its offset on reader's consumer
+// group. Nack is a no-op: kafka-go has no per-message requeue, so not
+// committing - and letting the group rebalance or the process restart -
+// is the redelivery mechanism. A caller that wants poison-message handling
+// should publish to a DLQ topic itself before Nack'ing, the way
+// service.eventBusClickEventConsumer does.
+func toKafkaDelivery(reader *kafka.Reader, msg kafka.Message) Delivery {
+	headers := make(map[string]string, len(msg.Headers))
+	for _, h := range msg.Headers {
+		headers[h.Key] = string(h.Value)
+	}
+
+	return Delivery{
+		Body:    msg.Value,
+		Headers: headers,
+		ack:     func() error { return reader.CommitMessages(context.Background(), msg) },
+		nack:    func(requeue bool) error { return nil },
+	}
+}
+
+func headerValue(headers []kafka.Header, key string) string {
+	for _, h := range headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func (b *kafkaBus) Close() error {
+	var firstErr error
+
+	if err := b.writer.Close(); err != nil {
+		firstErr = err
+	}
+	if b.replyReader != nil {
+		if err := b.replyReader.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	b.readersMu.Lock()
+	defer b.readersMu.Unlock()
+	for _, r := range b.readers {
+		if err := r.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
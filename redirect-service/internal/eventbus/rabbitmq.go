@@ -0,0 +1,185 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hoggir/re-path/redirect-service/internal/database"
+	"github.com/hoggir/re-path/redirect-service/internal/domain"
+	"github.com/hoggir/re-path/redirect-service/internal/logger"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// rabbitMQReplyToQueue is RabbitMQ's direct reply-to pseudo-queue, the same
+// one service.RabbitMQRPCService uses - see that file for why it's cheaper
+// than declaring an exclusive queue per call.
+const rabbitMQReplyToQueue = "amq.rabbitmq.reply-to"
+
+// rabbitMQBus adapts an already-connected *database.RabbitMQ to EventBus.
+// Publish uses the connection's confirm-mode PublishChannel(); Call
+// demultiplexes replies over Channel()'s direct reply-to consumer, started
+// lazily on the first Call.
+type rabbitMQBus struct {
+	rabbitmq *database.RabbitMQ
+	logger   logger.Logger
+
+	confirms chan amqp.Confirmation
+
+	replyOnce sync.Once
+	mu        sync.Mutex
+	pending   map[string]chan amqp.Delivery
+}
+
+func newRabbitMQBus(rabbitmq *database.RabbitMQ, log logger.Logger) (EventBus, error) {
+	return &rabbitMQBus{
+		rabbitmq: rabbitmq,
+		logger:   log,
+		confirms: rabbitmq.PublishChannel().NotifyPublish(make(chan amqp.Confirmation, 1)),
+		pending:  make(map[string]chan amqp.Delivery),
+	}, nil
+}
+
+func (b *rabbitMQBus) Publish(ctx context.Context, topic string, payload []byte) error {
+	err := b.rabbitmq.PublishChannel().PublishWithContext(
+		ctx,
+		"",
+		topic,
+		false,
+		false,
+		amqp.Publishing{
+			ContentType:  "application/json",
+			Body:         payload,
+			DeliveryMode: amqp.Persistent,
+			Timestamp:    time.Now(),
+		},
+	)
+	if err != nil {
+		return domain.ErrQueueError.WithContext("queue", topic).WithContext("operation", "Publish").Wrap(err)
+	}
+
+	select {
+	case confirm, ok := <-b.confirms:
+		if !ok || !confirm.Ack {
+			return domain.ErrQueueError.WithContext("queue", topic).WithMessage("broker nacked publish")
+		}
+	case <-time.After(5 * time.Second):
+		return domain.ErrQueueError.WithContext("queue", topic).WithMessage("timed out waiting for publisher confirm")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	return nil
+}
+
+func (b *rabbitMQBus) Call(ctx context.Context, topic string, payload []byte, timeout time.Duration) ([]byte, error) {
+	b.replyOnce.Do(func() { go b.consumeReplies() })
+
+	correlationID := uuid.New().String()
+	replyCh := make(chan amqp.Delivery, 1)
+	b.mu.Lock()
+	b.pending[correlationID] = replyCh
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		delete(b.pending, correlationID)
+		b.mu.Unlock()
+	}()
+
+	err := b.rabbitmq.Channel().PublishWithContext(
+		ctx,
+		"",
+		topic,
+		false,
+		false,
+		amqp.Publishing{
+			ContentType:   "application/json",
+			CorrelationId: correlationID,
+			ReplyTo:       rabbitMQReplyToQueue,
+			Body:          payload,
+			DeliveryMode:  amqp.Transient,
+			Timestamp:     time.Now(),
+		},
+	)
+	if err != nil {
+		return nil, domain.ErrQueueError.WithContext("queue", topic).WithContext("operation", "Publish").Wrap(err)
+	}
+
+	select {
+	case msg := <-replyCh:
+		return msg.Body, nil
+	case <-time.After(timeout):
+		return nil, domain.ErrTimeout.WithContext("queue", topic).WithContext("correlationId", correlationID)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// consumeReplies runs for the lifetime of the bus, dispatching each direct
+// reply-to delivery to the channel registered under its CorrelationId.
+func (b *rabbitMQBus) consumeReplies() {
+	deliveries, err := b.rabbitmq.Channel().Consume(rabbitMQReplyToQueue, "", true, false, false, false, nil)
+	if err != nil {
+		b.logger.Error("eventbus: failed to start RabbitMQ reply consumer", "error", err)
+		return
+	}
+
+	for msg := range deliveries {
+		b.mu.Lock()
+		ch, ok := b.pending[msg.CorrelationId]
+		b.mu.Unlock()
+		if !ok {
+			continue
+		}
+		ch <- msg
+	}
+}
+
+func (b *rabbitMQBus) Subscribe(ctx context.Context, topic, groupID string) (<-chan Delivery, error) {
+	deliveries, err := b.rabbitmq.Channel().Consume(topic, "", false, false, false, false, nil)
+	if err != nil {
+		return nil, domain.ErrQueueError.WithContext("queue", topic).WithContext("operation", "Consume").Wrap(err)
+	}
+
+	out := make(chan Delivery)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case msg, ok := <-deliveries:
+				if !ok {
+					return
+				}
+				out <- toRabbitMQDelivery(msg)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+func toRabbitMQDelivery(msg amqp.Delivery) Delivery {
+	headers := make(map[string]string, len(msg.Headers))
+	for k, v := range msg.Headers {
+		if s, ok := v.(string); ok {
+			headers[k] = s
+		}
+	}
+
+	return Delivery{
+		Body:    msg.Body,
+		Headers: headers,
+		ack:     func() error { return msg.Ack(false) },
+		nack:    func(requeue bool) error { return msg.Nack(false, requeue) },
+	}
+}
+
+// Close is a no-op: the underlying *database.RabbitMQ connection/channels
+// are owned by whoever constructed them (see database.NewRabbitMQ) and are
+// closed alongside the rest of the Server's resources, not by the bus.
+func (b *rabbitMQBus) Close() error {
+	return nil
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hoggir/re-path/redirect-service/internal/domain"
+	"github.com/hoggir/re-path/redirect-service/internal/dto"
+	"github.com/hoggir/re-path/redirect-service/internal/logger"
+	"github.com/hoggir/re-path/redirect-service/internal/service"
+)
+
+type AdminHandler struct {
+	sweeper service.Sweeper
+	logger  logger.Logger
+}
+
+func NewAdminHandler(sweeper service.Sweeper, log logger.Logger) *AdminHandler {
+	return &AdminHandler{
+		sweeper: sweeper,
+		logger:  log,
+	}
+}
+
+// Sweep triggers an on-demand sweep of expired URLs and/or lapsed cache entries.
+// @Summary Trigger an on-demand sweep
+// @Description Runs the expired-URL and/or lapsed-cache sweep immediately, bypassing the scheduler
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param scope query string false "expired, lapsed_cache, or both (default)"
+// @Success 200 {object} dto.Response{data=dto.SweepResponse}
+// @Router /api/admin/sweep [post]
+func (h *AdminHandler) Sweep(c *gin.Context) {
+	scope := c.Query("scope")
+
+	var results []service.SweepResult
+
+	if scope == "" || scope == "expired" {
+		result, err := h.sweeper.SweepExpired(c.Request.Context())
+		if err != nil {
+			h.logger.ErrorContext(c.Request.Context(), "admin sweep (expired) failed", "error", err)
+			dto.HandleError(c, domain.ErrInternalServer.Wrap(err))
+			return
+		}
+		results = append(results, result)
+	}
+
+	if scope == "" || scope == "lapsed_cache" {
+		result, err := h.sweeper.SweepLapsedCache(c.Request.Context())
+		if err != nil {
+			h.logger.ErrorContext(c.Request.Context(), "admin sweep (lapsed_cache) failed", "error", err)
+			dto.HandleError(c, domain.ErrInternalServer.Wrap(err))
+			return
+		}
+		results = append(results, result)
+	}
+
+	if len(results) == 0 {
+		dto.HandleError(c, domain.ErrInvalidInput.WithContext("scope", scope).WithMessage("scope must be expired, lapsed_cache, or omitted"))
+		return
+	}
+
+	dto.SuccessResponse(c, http.StatusOK, "sweep completed", dto.SweepResponse{Results: results})
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hoggir/re-path/redirect-service/internal/domain"
+	"github.com/hoggir/re-path/redirect-service/internal/dto"
+	"github.com/hoggir/re-path/redirect-service/internal/logger"
+	"github.com/hoggir/re-path/redirect-service/internal/service"
+)
+
+type AuthHandler struct {
+	jwtService service.JWTService
+	logger     logger.Logger
+}
+
+func NewAuthHandler(jwtService service.JWTService, log logger.Logger) *AuthHandler {
+	return &AuthHandler{
+		jwtService: jwtService,
+		logger:     log,
+	}
+}
+
+// Refresh rotates a refresh token for a new access+refresh pair.
+// @Summary Refresh an access token
+// @Description Redeems a refresh token for a new access+refresh pair, rotating the old one out of use
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body dto.RefreshRequest true "Refresh token"
+// @Success 200 {object} dto.Response{data=dto.TokenPairResponse}
+// @Failure 400 {object} dto.Response
+// @Failure 401 {object} dto.Response
+// @Router /auth/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req dto.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.HandleError(c, domain.ErrInvalidInput.WithContext("error", err.Error()))
+		return
+	}
+
+	pair, err := h.jwtService.RefreshTokenPair(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		dto.HandleError(c, err)
+		return
+	}
+
+	dto.SuccessResponse(c, http.StatusOK, "token refreshed", toTokenPairResponse(pair))
+}
+
+// Logout revokes the presented access token and every refresh token
+// outstanding for the caller.
+// @Summary Log out
+// @Description Revokes the presented access token and every refresh token issued to the caller, forcing re-authentication on every device
+// @Tags Auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} dto.Response
+// @Failure 401 {object} dto.Response
+// @Router /auth/logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	jti, _ := c.Get("jwt_id")
+
+	uid, _ := userID.(int)
+	jtiStr, _ := jti.(string)
+
+	if err := h.jwtService.Logout(c.Request.Context(), uid, jtiStr); err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "logout failed", "userID", uid, "error", err)
+		dto.HandleError(c, domain.ErrInternalServer.Wrap(err))
+		return
+	}
+
+	dto.SuccessResponse(c, http.StatusOK, "logged out", nil)
+}
+
+func toTokenPairResponse(pair *service.TokenPair) dto.TokenPairResponse {
+	return dto.TokenPairResponse{
+		AccessToken:      pair.AccessToken,
+		RefreshToken:     pair.RefreshToken,
+		TokenType:        "Bearer",
+		ExpiresIn:        pair.AccessExpiresIn,
+		RefreshExpiresIn: pair.RefreshExpiresIn,
+	}
+}
@@ -2,15 +2,21 @@ package handler
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/hoggir/re-path/redirect-service/internal/dto"
+	"github.com/hoggir/re-path/redirect-service/internal/service"
 )
 
-type HealthHandler struct{}
+// HealthHandler serves /health (kept for existing clients/swagger), plus the
+// /healthz and /readyz probes a load balancer or Kubernetes actually polls.
+type HealthHandler struct {
+	healthService service.HealthService
+}
 
-func NewHealthHandler() *HealthHandler {
-	return &HealthHandler{}
+func NewHealthHandler(healthService service.HealthService) *HealthHandler {
+	return &HealthHandler{healthService: healthService}
 }
 
 // Health check endpoint
@@ -27,3 +33,38 @@ func (h *HealthHandler) Health(c *gin.Context) {
 
 	dto.SuccessResponse(c, http.StatusOK, "Service is healthy", response)
 }
+
+// Liveness reports only that the process is up and serving requests; it
+// never touches MongoDB/Redis/RabbitMQ, so it can't be dragged down by a
+// dependency outage the way Readiness can.
+// @Summary Liveness probe
+// @Tags Health
+// @Success 200 {object} dto.Response{data=dto.HealthResponse}
+// @Router /healthz [get]
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	dto.SuccessResponse(c, http.StatusOK, "alive", dto.HealthResponse{Status: "UP", Service: "redirect-service"})
+}
+
+// Readiness reports the cached ReadinessReport from HealthService: 200 once
+// MongoDB, Redis, and RabbitMQ are all reachable and graceful shutdown
+// hasn't begun; 503 otherwise.
+// @Summary Readiness probe
+// @Tags Health
+// @Success 200 {object} dto.Response{data=service.ReadinessReport}
+// @Failure 503 {object} dto.Response{data=service.ReadinessReport}
+// @Router /readyz [get]
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	report := h.healthService.Readiness()
+
+	if !report.Ready {
+		c.JSON(http.StatusServiceUnavailable, dto.Response{
+			Success:   false,
+			Message:   "not ready",
+			Data:      report,
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	dto.SuccessResponse(c, http.StatusOK, "ready", report)
+}
@@ -3,12 +3,14 @@ package handler
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/hoggir/re-path/redirect-service/internal/config"
 	"github.com/hoggir/re-path/redirect-service/internal/domain"
 	"github.com/hoggir/re-path/redirect-service/internal/dto"
 	"github.com/hoggir/re-path/redirect-service/internal/logger"
+	"github.com/hoggir/re-path/redirect-service/internal/metrics"
 	"github.com/hoggir/re-path/redirect-service/internal/service"
 )
 
@@ -44,6 +46,9 @@ func NewRedirectHandler(
 // @Failure 404 {object} dto.Response
 // @Router /r/{shortUrl} [get]
 func (h *RedirectHandler) Redirect(c *gin.Context) {
+	start := time.Now()
+	defer func() { metrics.RedirectLatency.Observe(time.Since(start).Seconds()) }()
+
 	shortUrl := c.Param("shortUrl")
 
 	if shortUrl == "" {
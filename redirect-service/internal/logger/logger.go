@@ -6,6 +6,8 @@ import (
 	"os"
 
 	"github.com/hoggir/re-path/redirect-service/internal/config"
+	"github.com/hoggir/re-path/redirect-service/internal/reqctx"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Logger interface {
@@ -76,17 +78,39 @@ func (l *appLogger) Fatal(msg string, args ...any) {
 }
 
 func (l *appLogger) DebugContext(ctx context.Context, msg string, args ...any) {
-	l.logger.DebugContext(ctx, msg, args...)
+	l.logger.DebugContext(ctx, msg, contextArgs(ctx, args)...)
 }
 
 func (l *appLogger) InfoContext(ctx context.Context, msg string, args ...any) {
-	l.logger.InfoContext(ctx, msg, args...)
+	l.logger.InfoContext(ctx, msg, contextArgs(ctx, args)...)
 }
 
 func (l *appLogger) WarnContext(ctx context.Context, msg string, args ...any) {
-	l.logger.WarnContext(ctx, msg, args...)
+	l.logger.WarnContext(ctx, msg, contextArgs(ctx, args)...)
 }
 
 func (l *appLogger) ErrorContext(ctx context.Context, msg string, args ...any) {
-	l.logger.ErrorContext(ctx, msg, args...)
+	l.logger.ErrorContext(ctx, msg, contextArgs(ctx, args)...)
+}
+
+// contextArgs prepends request_id and trace_id - when ctx carries them - to
+// args, so every *Context log line is correlatable back to the HTTP request
+// and OpenTelemetry trace that produced it without every call site having to
+// thread them through manually.
+func contextArgs(ctx context.Context, args []any) []any {
+	var prefix []any
+
+	if requestID := reqctx.RequestID(ctx); requestID != "" {
+		prefix = append(prefix, "request_id", requestID)
+	}
+
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		prefix = append(prefix, "trace_id", sc.TraceID().String())
+	}
+
+	if len(prefix) == 0 {
+		return args
+	}
+
+	return append(prefix, args...)
 }
@@ -0,0 +1,73 @@
+// Package metrics holds the Prometheus collectors shared across packages,
+// as opposed to the metrics each service already registers for itself next
+// to the code it measures (e.g. service.rpcDuration, service.dashboardCacheTotal).
+// RabbitMQRPCService.Call's per-queue duration and outcome are already
+// covered by service.rpcDuration/rpcCallsTotal, so this package doesn't
+// duplicate those; it exists instead for the metrics nothing else owns -
+// HTTP-level metrics, end-to-end redirect latency, and a generic
+// per-service cache hit/miss/invalidation counter that services can report
+// into alongside their own more detailed metrics.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// HTTPRequestsInFlight is the number of HTTP requests currently being
+// handled, set by middleware.MetricsMiddleware.
+var HTTPRequestsInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "repath_http_requests_in_flight",
+	Help: "Number of HTTP requests currently being served.",
+})
+
+// HTTPRequestDuration is every HTTP request's handler time, labelled by
+// route (gin's registered pattern, not the raw path, so /r/:shortUrl
+// doesn't explode into one series per short code) and response status.
+var HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "repath_http_request_duration_seconds",
+	Help:    "HTTP request duration in seconds, by route and status.",
+	Buckets: prometheus.DefBuckets,
+}, []string{"method", "route", "status"})
+
+// RedirectLatency is the end-to-end latency of the redirect path
+// (RedirectHandler.Redirect), separate from HTTPRequestDuration's per-route
+// bucket because it's the one route this service exists to keep fast.
+var RedirectLatency = promauto.NewHistogram(prometheus.HistogramOpts{
+	Name:    "repath_redirect_latency_seconds",
+	Help:    "End-to-end latency of the /r/:shortUrl redirect lookup, in seconds.",
+	Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+})
+
+// cacheResultTotal is the backing CounterVec for ObserveCacheHit/Miss/
+// Invalidation: service names the caller (e.g. "dashboard", "redirect"),
+// result is "hit", "miss", or "invalidation".
+var cacheResultTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "repath_cache_result_total",
+	Help: "Cache lookups across services, by service and result.",
+}, []string{"service", "result"})
+
+// ObserveCacheHit records a cache hit attributed to service.
+func ObserveCacheHit(service string) {
+	cacheResultTotal.WithLabelValues(service, "hit").Inc()
+}
+
+// ObserveCacheMiss records a cache miss attributed to service.
+func ObserveCacheMiss(service string) {
+	cacheResultTotal.WithLabelValues(service, "miss").Inc()
+}
+
+// ObserveCacheInvalidation records a cache entry being invalidated,
+// attributed to service.
+func ObserveCacheInvalidation(service string) {
+	cacheResultTotal.WithLabelValues(service, "invalidation").Inc()
+}
+
+// Handler serves the default Prometheus registry in the text exposition
+// format, for mounting at /metrics.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
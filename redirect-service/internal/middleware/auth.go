@@ -15,6 +15,9 @@ const (
 	UserIDKey           = "user_id"
 	UserEmailKey        = "user_email"
 	UserRoleKey         = "user_role"
+	// JWTIDKey is the presented access token's jti, so handlers like
+	// AuthHandler.Logout can revoke that exact token without re-parsing it.
+	JWTIDKey = "jwt_id"
 )
 
 func JWTAuthMiddleware(jwtService service.JWTService) gin.HandlerFunc {
@@ -39,7 +42,7 @@ func JWTAuthMiddleware(jwtService service.JWTService) gin.HandlerFunc {
 			return
 		}
 
-		claims, err := jwtService.ValidateToken(tokenString)
+		claims, err := jwtService.ValidateToken(c.Request.Context(), tokenString)
 		if err != nil {
 			dto.HandleError(c, err)
 			c.Abort()
@@ -49,7 +52,27 @@ func JWTAuthMiddleware(jwtService service.JWTService) gin.HandlerFunc {
 		c.Set(UserIDKey, claims.GetUserIDAsInt())
 		c.Set(UserEmailKey, claims.Email)
 		c.Set(UserRoleKey, claims.Role)
+		c.Set(JWTIDKey, claims.ID)
 
 		c.Next()
 	}
 }
+
+// RequireRole must run after JWTAuthMiddleware has set UserRoleKey. It
+// rejects the request with ErrForbidden unless the caller's role is one of
+// allowedRoles.
+func RequireRole(allowedRoles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, _ := c.Get(UserRoleKey)
+
+		for _, allowed := range allowedRoles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+
+		dto.HandleError(c, domain.ErrForbidden.WithMessage("insufficient permissions for this operation"))
+		c.Abort()
+	}
+}
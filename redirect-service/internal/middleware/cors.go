@@ -1,44 +1,78 @@
 package middleware
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/hoggir/re-path/redirect-service/internal/config"
 )
 
-func CORSMiddleware(cfg *config.Config) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		origins := strings.Split(cfg.CORS.AllowOrigins, ",")
-		methods := strings.Split(cfg.CORS.AllowMethods, ",")
-		headers := strings.Split(cfg.CORS.AllowHeaders, ",")
+// CORSPolicy is a compiled CORS configuration for one route group. Build one
+// with NewCORSPolicy and register it with a group via Middleware(); the
+// policy itself is stateless and safe to share across groups.
+type CORSPolicy struct {
+	origins          *OriginMatcher
+	allowMethods     string
+	allowHeaders     string
+	allowCredentials bool
+}
 
+// NewCORSPolicy compiles cfg into a CORSPolicy, ready for repeated use
+// against every request instead of re-splitting the config on each one. It
+// refuses a wildcard origin list paired with AllowCredentials: true, since
+// "Access-Control-Allow-Origin: *" with "Access-Control-Allow-Credentials:
+// true" is rejected by browsers and isn't a valid combination to serve.
+func NewCORSPolicy(cfg config.CORSConfig) (*CORSPolicy, error) {
+	origins, err := NewOriginMatcher(cfg.AllowOrigins)
+	if err != nil {
+		return nil, fmt.Errorf("compile CORS origin matcher: %w", err)
+	}
+
+	if cfg.AllowCredentials && origins.AllowsAny() {
+		return nil, fmt.Errorf("CORS config invalid: AllowCredentials is true but AllowOrigins allows any origin (%q)", cfg.AllowOrigins)
+	}
+
+	return &CORSPolicy{
+		origins:          origins,
+		allowMethods:     normalizeCSV(cfg.AllowMethods),
+		allowHeaders:     normalizeCSV(cfg.AllowHeaders),
+		allowCredentials: cfg.AllowCredentials,
+	}, nil
+}
+
+// Middleware returns a gin.HandlerFunc enforcing this policy. Register it on
+// the engine for a process-wide default, or on a specific *gin.RouterGroup
+// to give that group its own policy - e.g. a tighter one for /api/dashboard
+// than the permissive one on /r/:shortUrl.
+func (p *CORSPolicy) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
 		origin := c.Request.Header.Get("Origin")
-		allowed := false
-		for _, allowedOrigin := range origins {
-			allowedOrigin = strings.TrimSpace(allowedOrigin)
-			if allowedOrigin == "*" || allowedOrigin == origin {
-				allowed = true
-				break
-			}
-		}
 
-		if allowed {
-			if origin != "" {
-				c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
-			} else if len(origins) > 0 && origins[0] == "*" {
-				c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		// The allowed set depends on the request's Origin header, so caches
+		// (browser, CDN) must key on it rather than serving one response to
+		// every origin.
+		c.Writer.Header().Add("Vary", "Origin")
+
+		// A group policy layered on top of the engine-wide one replaces,
+		// rather than adds to, the origin headers that one already set.
+		c.Writer.Header().Del("Access-Control-Allow-Origin")
+		c.Writer.Header().Del("Access-Control-Allow-Credentials")
+
+		if origin != "" && p.origins.Match(origin) {
+			c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+			if p.allowCredentials {
+				c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 			}
+		} else if origin == "" && p.origins.AllowsAny() {
+			c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		}
 
-		c.Writer.Header().Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
-		c.Writer.Header().Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", p.allowMethods)
+		c.Writer.Header().Set("Access-Control-Allow-Headers", p.allowHeaders)
 		c.Writer.Header().Set("Access-Control-Max-Age", "86400")
-
 		c.Writer.Header().Set("Access-Control-Expose-Headers", "Location")
 
-		// Handle preflight OPTIONS request
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
 			return
@@ -47,3 +81,11 @@ func CORSMiddleware(cfg *config.Config) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+func normalizeCSV(csv string) string {
+	parts := strings.Split(csv, ",")
+	for i, p := range parts {
+		parts[i] = strings.TrimSpace(p)
+	}
+	return strings.Join(parts, ", ")
+}
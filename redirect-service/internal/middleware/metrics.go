@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hoggir/re-path/redirect-service/internal/metrics"
+)
+
+// MetricsMiddleware tracks in-flight requests and per-route duration for
+// metrics.HTTPRequestsInFlight/HTTPRequestDuration. Register early - after
+// RequestContextMiddleware so tracing/request-ID setup isn't counted against
+// the in-flight gauge, but before Recovery so a panic's eventual 500 status
+// still gets observed. Uses c.FullPath() (the registered route pattern, not
+// the raw URL) so /r/:shortUrl reports as one series instead of one per
+// short code.
+func MetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		metrics.HTTPRequestsInFlight.Inc()
+		defer metrics.HTTPRequestsInFlight.Dec()
+
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.HTTPRequestDuration.WithLabelValues(
+			c.Request.Method,
+			route,
+			strconv.Itoa(c.Writer.Status()),
+		).Observe(time.Since(start).Seconds())
+	}
+}
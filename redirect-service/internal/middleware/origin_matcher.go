@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// originEntry is one compiled rule out of a CORS_ALLOW_ORIGINS list. It's one
+// of three kinds: an exact origin, a "*.example.com" wildcard subdomain, or a
+// "re:<pattern>" regex matched against the whole Origin header.
+type originEntry struct {
+	exact   string
+	suffix  string // wildcard: ".example.com", matched against the Origin's host
+	pattern *regexp.Regexp
+}
+
+func (e originEntry) match(origin string) bool {
+	switch {
+	case e.pattern != nil:
+		return e.pattern.MatchString(origin)
+	case e.suffix != "":
+		u, err := url.Parse(origin)
+		if err != nil {
+			return false
+		}
+		return strings.HasSuffix(strings.ToLower(u.Hostname()), e.suffix)
+	default:
+		return strings.EqualFold(e.exact, origin)
+	}
+}
+
+// OriginMatcher is a compiled CORS_ALLOW_ORIGINS list. It's built once at
+// startup (by NewCORSPolicy) rather than re-split on every request.
+type OriginMatcher struct {
+	wildcardAny bool // a bare "*" entry - allow any origin
+	entries     []originEntry
+}
+
+// NewOriginMatcher compiles a comma-separated origin list. Each entry is one
+// of:
+//   - "*"                     matches any origin
+//   - "https://foo.com"       exact match
+//   - "*.example.com"         matches any subdomain of example.com, any scheme
+//   - "re:^https://.*\.x\.com$"  regex matched against the full Origin header
+func NewOriginMatcher(originsCSV string) (*OriginMatcher, error) {
+	m := &OriginMatcher{}
+
+	for _, raw := range strings.Split(originsCSV, ",") {
+		entry := strings.TrimSpace(raw)
+		if entry == "" {
+			continue
+		}
+
+		switch {
+		case entry == "*":
+			m.wildcardAny = true
+		case strings.HasPrefix(entry, "re:"):
+			pattern, err := regexp.Compile(strings.TrimPrefix(entry, "re:"))
+			if err != nil {
+				return nil, fmt.Errorf("compile CORS origin regex %q: %w", entry, err)
+			}
+			m.entries = append(m.entries, originEntry{pattern: pattern})
+		case strings.HasPrefix(entry, "*."):
+			m.entries = append(m.entries, originEntry{suffix: strings.ToLower(strings.TrimPrefix(entry, "*"))})
+		default:
+			m.entries = append(m.entries, originEntry{exact: entry})
+		}
+	}
+
+	return m, nil
+}
+
+// AllowsAny reports whether this matcher admits every origin (a bare "*"
+// entry), which the strict-mode check in NewCORSPolicy refuses to pair with
+// Access-Control-Allow-Credentials: true.
+func (m *OriginMatcher) AllowsAny() bool {
+	return m.wildcardAny
+}
+
+// Match reports whether origin is allowed by this matcher.
+func (m *OriginMatcher) Match(origin string) bool {
+	if m.wildcardAny {
+		return true
+	}
+	for _, e := range m.entries {
+		if e.match(origin) {
+			return true
+		}
+	}
+	return false
+}
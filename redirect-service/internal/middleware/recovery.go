@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hoggir/re-path/redirect-service/internal/app/http/response"
+	"github.com/hoggir/re-path/redirect-service/internal/logger"
+)
+
+// ProblemRecoveryMiddleware replaces gin.Recovery() with a handler that
+// turns a panic into an RFC 7807 problem+json body (via
+// response.ProblemCode) instead of gin's plain-text 500, so a crashing
+// handler still produces a response a client's existing error-code branching
+// can understand. Register in place of gin.Recovery(), before any
+// middleware that can itself panic.
+func ProblemRecoveryMiddleware(log logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.ErrorContext(c.Request.Context(), "panic recovered", "panic", r, "path", c.Request.URL.Path)
+				response.ProblemCode(c, http.StatusInternalServerError, response.ErrCodeInternal, "An unexpected error occurred")
+				c.Abort()
+			}
+		}()
+
+		c.Next()
+	}
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/hoggir/re-path/redirect-service/internal/reqctx"
+	"github.com/hoggir/re-path/redirect-service/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+// RequestIDHeader is generated (or adopted from an inbound request) and
+// echoed back on the response, so a client and this service agree on one ID
+// for correlating logs.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestContextMiddleware assigns a request ID, extracts an incoming
+// traceparent header if present, and opens a span covering the whole
+// handler chain - every RedirectService, CacheService, and repository call
+// downstream that shares this request's context becomes a child span of it,
+// so a redirect forms one trace end to end. It also stamps the request's
+// start time on the context, so response.Meta can report duration_ms without
+// every handler threading a timer through. Register before gin.Logger() so
+// the request ID is available to every subsequent middleware and handler.
+func RequestContextMiddleware() gin.HandlerFunc {
+	propagator := propagation.TraceContext{}
+
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" {
+			requestID = uuid.New().String()
+		}
+		c.Writer.Header().Set(RequestIDHeader, requestID)
+
+		ctx := propagator.Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		ctx = reqctx.WithRequestID(ctx, requestID)
+		ctx = reqctx.WithStartTime(ctx, time.Now())
+
+		ctx, span := tracing.Tracer().Start(ctx, c.FullPath())
+		defer span.End()
+
+		span.SetAttributes(
+			attribute.String("request_id", requestID),
+			semconv.HTTPMethod(c.Request.Method),
+			semconv.HTTPRoute(c.FullPath()),
+		)
+
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		span.SetAttributes(semconv.HTTPStatusCode(c.Writer.Status()))
+	}
+}
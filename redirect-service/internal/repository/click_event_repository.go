@@ -8,10 +8,15 @@ import (
 	"github.com/hoggir/re-path/redirect-service/internal/database"
 	"github.com/hoggir/re-path/redirect-service/internal/domain"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type ClickEventRepository interface {
 	Create(ctx context.Context, clickEvent *domain.ClickEvent) error
+	// CreateMany inserts a batch of click events in a single InsertMany
+	// round trip, for callers (the click-event ingester) that buffer events
+	// before writing them through.
+	CreateMany(ctx context.Context, clickEvents []*domain.ClickEvent) error
 }
 
 type clickEventRepository struct {
@@ -37,3 +42,22 @@ func (r *clickEventRepository) Create(ctx context.Context, clickEvent *domain.Cl
 
 	return nil
 }
+
+func (r *clickEventRepository) CreateMany(ctx context.Context, clickEvents []*domain.ClickEvent) error {
+	if len(clickEvents) == 0 {
+		return nil
+	}
+
+	docs := make([]interface{}, len(clickEvents))
+	for i, evt := range clickEvents {
+		docs[i] = evt
+	}
+
+	// ordered=false so one bad document doesn't abort the rest of the batch.
+	_, err := r.collection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	if err != nil {
+		return fmt.Errorf("failed to create click event batch: %w", err)
+	}
+
+	return nil
+}
@@ -2,42 +2,41 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"time"
 
-	"github.com/hoggir/re-path/redirect-service/internal/database"
 	"github.com/hoggir/re-path/redirect-service/internal/domain"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
+	"github.com/hoggir/re-path/redirect-service/internal/storage"
 )
 
 type URLRepository interface {
 	FindByShortCode(ctx context.Context, shortCode string) (*domain.FindByShortCode, error)
 	IncrementClickCount(ctx context.Context, shortCode string) error
+	// IncrementClickCounts applies a batch of per-shortCode click deltas in a
+	// single round trip, for callers (the click-count batcher) that coalesce
+	// individual increments before writing them through.
+	IncrementClickCounts(ctx context.Context, deltas map[string]int) error
+	// FindExpired returns up to limit active URLs whose expiresAt has passed,
+	// projecting only the fields the sweeper needs.
+	FindExpired(ctx context.Context, limit int64) ([]domain.ExpiredURLRef, error)
+	DeactivateByShortCode(ctx context.Context, shortCode string) error
+	DeleteByShortCode(ctx context.Context, shortCode string) error
 }
 
 type urlRepository struct {
-	db         *database.MongoDB
-	collection *mongo.Collection
+	store storage.URLStore
 }
 
-func NewURLRepository(db *database.MongoDB) URLRepository {
+func NewURLRepository(store storage.URLStore) URLRepository {
 	return &urlRepository{
-		db:         db,
-		collection: db.Collection(domain.URL{}.CollectionName()),
+		store: store,
 	}
 }
 
 func (r *urlRepository) FindByShortCode(ctx context.Context, shortCode string) (*domain.FindByShortCode, error) {
-	var url domain.FindByShortCode
-
-	filter := bson.M{
-		"shortCode": shortCode,
-	}
-
-	err := r.collection.FindOne(ctx, filter, options.FindOne().SetProjection(bson.M{"userId": 1, "originalUrl": 1, "isActive": 1, "expiresAt": 1, "_id": 0})).Decode(&url)
+	url, err := r.store.FindByShortCode(ctx, shortCode)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
+		if errors.Is(err, storage.ErrNotFound) {
 			return nil, domain.ErrURLNotFound.WithContext("shortCode", shortCode)
 		}
 		return nil, domain.ErrDatabaseError.
@@ -54,37 +53,67 @@ func (r *urlRepository) FindByShortCode(ctx context.Context, shortCode string) (
 
 	if url.ExpiresAt != nil && url.ExpiresAt.Before(time.Now()) {
 		return nil, domain.ErrURLExpired.
+			WithArgs(shortCode, url.ExpiresAt.Format(time.RFC3339)).
 			WithContext("shortCode", shortCode).
 			WithContext("expiresAt", url.ExpiresAt)
 	}
 
-	return &url, nil
+	return url, nil
 }
 
 func (r *urlRepository) IncrementClickCount(ctx context.Context, shortCode string) error {
-	filter := bson.M{
-		"shortCode": shortCode,
+	if err := r.store.IncrementClickCount(ctx, shortCode); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return domain.ErrURLNotFound.WithContext("shortCode", shortCode)
+		}
+		return domain.ErrDatabaseError.
+			WithContext("shortCode", shortCode).
+			WithContext("operation", "IncrementClickCount").
+			Wrap(err)
 	}
 
-	update := bson.M{
-		"$inc": bson.M{
-			"clickCount": 1,
-		},
-		"$set": bson.M{
-			"updatedAt": time.Now(),
-		},
+	return nil
+}
+
+func (r *urlRepository) IncrementClickCounts(ctx context.Context, deltas map[string]int) error {
+	if err := r.store.IncrementClickCounts(ctx, deltas); err != nil {
+		return domain.ErrDatabaseError.
+			WithContext("operation", "IncrementClickCounts").
+			WithContext("batchSize", len(deltas)).
+			Wrap(err)
 	}
 
-	result, err := r.collection.UpdateOne(ctx, filter, update)
+	return nil
+}
+
+func (r *urlRepository) FindExpired(ctx context.Context, limit int64) ([]domain.ExpiredURLRef, error) {
+	refs, err := r.store.FindExpired(ctx, limit)
 	if err != nil {
+		return nil, domain.ErrDatabaseError.
+			WithContext("operation", "FindExpired").
+			Wrap(err)
+	}
+
+	return refs, nil
+}
+
+func (r *urlRepository) DeactivateByShortCode(ctx context.Context, shortCode string) error {
+	if err := r.store.DeactivateByShortCode(ctx, shortCode); err != nil {
 		return domain.ErrDatabaseError.
 			WithContext("shortCode", shortCode).
-			WithContext("operation", "IncrementClickCount").
+			WithContext("operation", "DeactivateByShortCode").
 			Wrap(err)
 	}
 
-	if result.MatchedCount == 0 {
-		return domain.ErrURLNotFound.WithContext("shortCode", shortCode)
+	return nil
+}
+
+func (r *urlRepository) DeleteByShortCode(ctx context.Context, shortCode string) error {
+	if err := r.store.DeleteByShortCode(ctx, shortCode); err != nil {
+		return domain.ErrDatabaseError.
+			WithContext("shortCode", shortCode).
+			WithContext("operation", "DeleteByShortCode").
+			Wrap(err)
 	}
 
 	return nil
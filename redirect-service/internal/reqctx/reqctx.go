@@ -0,0 +1,42 @@
+// Package reqctx carries per-request values across context.Context
+// boundaries. It exists as its own package (rather than living in
+// middleware, which sets it) so logger and response can read it back
+// without a middleware -> logger/response import cycle.
+package reqctx
+
+import (
+	"context"
+	"time"
+)
+
+type requestIDKeyType struct{}
+type startTimeKeyType struct{}
+
+var (
+	requestIDKey = requestIDKeyType{}
+	startTimeKey = startTimeKeyType{}
+)
+
+// WithRequestID returns a copy of ctx carrying id, retrievable via RequestID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID returns the request ID stored on ctx, or "" if none is present.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// WithStartTime returns a copy of ctx carrying t as the moment the request
+// started being handled, retrievable via StartTime.
+func WithStartTime(ctx context.Context, t time.Time) context.Context {
+	return context.WithValue(ctx, startTimeKey, t)
+}
+
+// StartTime returns the request's start time and true, or the zero time and
+// false if ctx carries none.
+func StartTime(ctx context.Context) (time.Time, bool) {
+	t, ok := ctx.Value(startTimeKey).(time.Time)
+	return t, ok
+}
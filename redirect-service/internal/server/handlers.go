@@ -7,6 +7,8 @@ type Handlers struct {
 	Redirect  *handler.RedirectHandler
 	Health    *handler.HealthHandler
 	Dashboard *handler.DashboardHandler
+	Admin     *handler.AdminHandler
+	Auth      *handler.AuthHandler
 }
 
 // NewHandlers creates a new Handlers instance
@@ -14,10 +16,14 @@ func NewHandlers(
 	redirectHandler *handler.RedirectHandler,
 	healthHandler *handler.HealthHandler,
 	dashboardHandler *handler.DashboardHandler,
+	adminHandler *handler.AdminHandler,
+	authHandler *handler.AuthHandler,
 ) *Handlers {
 	return &Handlers{
 		Redirect:  redirectHandler,
 		Health:    healthHandler,
 		Dashboard: dashboardHandler,
+		Admin:     adminHandler,
+		Auth:      authHandler,
 	}
 }
@@ -1,22 +1,68 @@
 package server
 
 import (
+	"fmt"
+
 	"github.com/gin-gonic/gin"
 	"github.com/hoggir/re-path/redirect-service/internal/config"
+	"github.com/hoggir/re-path/redirect-service/internal/logger"
 	"github.com/hoggir/re-path/redirect-service/internal/middleware"
 	"github.com/hoggir/re-path/redirect-service/internal/service"
 )
 
 // Middlewares groups all middleware functions
 type Middlewares struct {
-	CORS gin.HandlerFunc
-	Auth gin.HandlerFunc
+	RequestContext gin.HandlerFunc
+	// CORS is the default, permissive policy applied process-wide; routes
+	// that need a tighter policy layer CORSDashboard on top of it instead of
+	// replacing it, so OPTIONS preflights (handled by CORS) keep working.
+	CORS              gin.HandlerFunc
+	CORSDashboard     gin.HandlerFunc
+	Auth              gin.HandlerFunc
+	RateLimitRedirect gin.HandlerFunc
+	// RateLimitRedirectByShortCode layers a per-short-code bucket on top of
+	// RateLimitRedirect's per-IP one; both apply to the redirect route.
+	RateLimitRedirectByShortCode gin.HandlerFunc
+	RateLimitDashboard           gin.HandlerFunc
+	// Recovery replaces gin.Recovery() so a panic comes back as a
+	// problem+json body instead of gin's plain-text 500.
+	Recovery gin.HandlerFunc
+	// Metrics tracks in-flight requests and per-route duration; see
+	// middleware.MetricsMiddleware.
+	Metrics gin.HandlerFunc
 }
 
 // NewMiddlewares creates a new Middlewares instance
-func NewMiddlewares(cfg *config.Config, jwtService service.JWTService) *Middlewares {
-	return &Middlewares{
-		CORS: middleware.CORSMiddleware(cfg),
-		Auth: middleware.JWTAuthMiddleware(jwtService),
+func NewMiddlewares(cfg *config.Config, jwtService service.JWTService, rateLimiter service.RateLimiter, log logger.Logger) (*Middlewares, error) {
+	redirectRule, shortCodeRule, dashboardRule := cfg.RateLimit.Redirect, cfg.RateLimit.ShortCode, cfg.RateLimit.Dashboard
+	if !cfg.RateLimit.Enabled {
+		redirectRule, shortCodeRule, dashboardRule = config.RateLimitRule{}, config.RateLimitRule{}, config.RateLimitRule{}
+	}
+
+	corsPolicy, err := middleware.NewCORSPolicy(cfg.CORS)
+	if err != nil {
+		return nil, fmt.Errorf("build default CORS policy: %w", err)
 	}
+
+	dashboardPolicy := corsPolicy
+	if cfg.CORS.DashboardAllowOrigins != "" {
+		dashboardCfg := cfg.CORS
+		dashboardCfg.AllowOrigins = cfg.CORS.DashboardAllowOrigins
+		dashboardPolicy, err = middleware.NewCORSPolicy(dashboardCfg)
+		if err != nil {
+			return nil, fmt.Errorf("build dashboard CORS policy: %w", err)
+		}
+	}
+
+	return &Middlewares{
+		RequestContext:               middleware.RequestContextMiddleware(),
+		CORS:                         corsPolicy.Middleware(),
+		CORSDashboard:                dashboardPolicy.Middleware(),
+		Auth:                         middleware.JWTAuthMiddleware(jwtService),
+		RateLimitRedirect:            middleware.RateLimitMiddleware(rateLimiter, "redirect", redirectRule, middleware.ByClientIP, log),
+		RateLimitRedirectByShortCode: middleware.RateLimitMiddleware(rateLimiter, "redirect", shortCodeRule, middleware.ByShortCode, log),
+		RateLimitDashboard:           middleware.RateLimitMiddleware(rateLimiter, "dashboard", dashboardRule, middleware.ByUserID, log),
+		Recovery:                     middleware.ProblemRecoveryMiddleware(log),
+		Metrics:                      middleware.MetricsMiddleware(),
+	}, nil
 }
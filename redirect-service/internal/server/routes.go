@@ -6,10 +6,13 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 
 	_ "github.com/hoggir/re-path/redirect-service/docs"
+	"github.com/hoggir/re-path/redirect-service/internal/metrics"
+	"github.com/hoggir/re-path/redirect-service/internal/middleware"
 )
 
 func (s *Server) registerRoutes(r *gin.Engine) {
 	s.registerPublicRoutes(r)
+	s.registerAuthRoutes(r)
 	s.registerAPIRoutes(r)
 	s.registerRedirectRoutes(r)
 }
@@ -17,6 +20,30 @@ func (s *Server) registerRoutes(r *gin.Engine) {
 func (s *Server) registerPublicRoutes(r *gin.Engine) {
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	r.GET("/health", s.Handlers.Health.Health)
+	r.GET("/healthz", s.Handlers.Health.Liveness)
+	r.GET("/readyz", s.Handlers.Health.Readiness)
+
+	// An empty Metrics.Port means /metrics has nowhere else to live, so it's
+	// mounted here; a non-empty port instead runs it on its own listener
+	// (see cmd/main.go) and this route is skipped.
+	if s.Config.Metrics.Port == "" {
+		r.GET("/metrics", gin.WrapH(metrics.Handler()))
+	}
+}
+
+// registerAuthRoutes wires the token refresh/logout endpoints. Refresh is
+// public - the caller authenticates with a refresh token, not an access
+// token - while logout needs the Auth middleware to know which user and jti
+// to revoke.
+func (s *Server) registerAuthRoutes(r *gin.Engine) {
+	auth := r.Group("/auth")
+	{
+		auth.POST("/refresh", s.Handlers.Auth.Refresh)
+
+		protected := auth.Group("")
+		protected.Use(s.Middlewares.Auth)
+		protected.POST("/logout", s.Handlers.Auth.Logout)
+	}
 }
 
 func (s *Server) registerAPIRoutes(r *gin.Engine) {
@@ -29,12 +56,14 @@ func (s *Server) registerAPIRoutes(r *gin.Engine) {
 
 func (s *Server) registerProtectedAPIRoutes(rg *gin.RouterGroup) {
 	protected := rg.Group("")
+	protected.Use(s.Middlewares.CORSDashboard)
 	protected.Use(s.Middlewares.Auth)
 	{
-		protected.GET("/dashboard", s.Handlers.Dashboard.GetDashboardByShortUrl)
+		protected.GET("/dashboard", s.Middlewares.RateLimitDashboard, s.Handlers.Dashboard.GetDashboardByShortUrl)
+		protected.POST("/admin/sweep", middleware.RequireRole("admin"), s.Handlers.Admin.Sweep)
 	}
 }
 
 func (s *Server) registerRedirectRoutes(r *gin.Engine) {
-	r.GET("/r/:shortUrl", s.Handlers.Redirect.Redirect)
+	r.GET("/r/:shortUrl", s.Middlewares.RateLimitRedirect, s.Middlewares.RateLimitRedirectByShortCode, s.Handlers.Redirect.Redirect)
 }
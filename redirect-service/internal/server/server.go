@@ -1,38 +1,85 @@
 package server
 
 import (
+	"context"
+
 	"github.com/gin-gonic/gin"
 	"github.com/hoggir/re-path/redirect-service/internal/config"
 	"github.com/hoggir/re-path/redirect-service/internal/database"
+	"github.com/hoggir/re-path/redirect-service/internal/eventbus"
+	"github.com/hoggir/re-path/redirect-service/internal/logger"
+	"github.com/hoggir/re-path/redirect-service/internal/service"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 type Server struct {
-	Config      *config.Config
-	Router      *gin.Engine
-	Handlers    *Handlers
-	Middlewares *Middlewares
-	MongoDB     *database.MongoDB
-	Redis       *database.Redis
+	Config             *config.Config
+	Router             *gin.Engine
+	Handlers           *Handlers
+	Middlewares        *Middlewares
+	Logger             logger.Logger
+	MongoDB            *database.MongoDB
+	Redis              *database.Redis
+	EventBus           eventbus.EventBus
+	CacheInvalidator   service.CacheInvalidator
+	Sweeper            service.Sweeper
+	ClickBatcher       service.ClickCountBatcher
+	JWTService         service.JWTService
+	ClickEventIngester service.ClickEventIngester
+	ClickEventConsumer service.ClickEventConsumer
+	HealthService      service.HealthService
+	GeoIPUpdater       *service.GeoIPUpdater
+	TracerProvider     *sdktrace.TracerProvider
 }
 
 func New(
 	cfg *config.Config,
 	handlers *Handlers,
 	middlewares *Middlewares,
+	log logger.Logger,
 	mongoDB *database.MongoDB,
 	redis *database.Redis,
+	bus eventbus.EventBus,
+	cacheInvalidator service.CacheInvalidator,
+	sweeper service.Sweeper,
+	clickBatcher service.ClickCountBatcher,
+	jwtService service.JWTService,
+	clickEventIngester service.ClickEventIngester,
+	clickEventConsumer service.ClickEventConsumer,
+	healthService service.HealthService,
+	geoIPUpdater *service.GeoIPUpdater,
+	tracerProvider *sdktrace.TracerProvider,
 ) *Server {
 	gin.SetMode(cfg.Server.GinMode)
 
 	srv := &Server{
-		Config:      cfg,
-		Handlers:    handlers,
-		Middlewares: middlewares,
-		MongoDB:     mongoDB,
-		Redis:       redis,
+		Config:             cfg,
+		Handlers:           handlers,
+		Middlewares:        middlewares,
+		Logger:             log,
+		MongoDB:            mongoDB,
+		Redis:              redis,
+		EventBus:           bus,
+		CacheInvalidator:   cacheInvalidator,
+		Sweeper:            sweeper,
+		ClickBatcher:       clickBatcher,
+		JWTService:         jwtService,
+		ClickEventIngester: clickEventIngester,
+		ClickEventConsumer: clickEventConsumer,
+		HealthService:      healthService,
+		GeoIPUpdater:       geoIPUpdater,
+		TracerProvider:     tracerProvider,
 	}
 
 	srv.setupRouter()
+	srv.startCacheInvalidator()
+	srv.startSweeper()
+	srv.startClickBatcher()
+	srv.startJWKSRefresh()
+	srv.startClickEventIngester()
+	srv.startClickEventConsumer()
+	srv.startHealthService()
+	srv.startGeoIPUpdater()
 
 	return srv
 }
@@ -40,15 +87,97 @@ func New(
 func (s *Server) setupRouter() {
 	r := gin.New()
 
+	r.Use(s.Middlewares.RequestContext)
 	r.Use(gin.Logger())
-	r.Use(gin.Recovery())
+	r.Use(s.Middlewares.Metrics)
+	r.Use(s.Middlewares.Recovery)
 	r.Use(s.Middlewares.CORS)
+	// Route groups that need a stricter policy (e.g. /api/dashboard) layer
+	// their own CORSPolicy.Middleware() on top of this one in routes.go.
 
 	s.registerRoutes(r)
 
 	s.Router = r
 }
 
+// startCacheInvalidator subscribes this replica to the cross-replica cache
+// invalidation channel for the lifetime of the process.
+func (s *Server) startCacheInvalidator() {
+	if s.CacheInvalidator == nil {
+		return
+	}
+	go s.CacheInvalidator.Run(context.Background())
+}
+
+// startSweeper launches the periodic expired-URL / lapsed-cache sweep. Leader
+// election happens inside Sweeper itself, so every replica can call this.
+func (s *Server) startSweeper() {
+	if s.Sweeper == nil {
+		return
+	}
+	go s.Sweeper.Run(context.Background())
+}
+
+// startClickBatcher launches the click-count batcher's flush loop for the
+// lifetime of the process; the redirect path only ever enqueues into it.
+func (s *Server) startClickBatcher() {
+	if s.ClickBatcher == nil {
+		return
+	}
+	go s.ClickBatcher.Run(context.Background())
+}
+
+// startJWKSRefresh launches the JWT service's background JWKS refresh loop;
+// a no-op when it's configured for static HMAC verification.
+func (s *Server) startJWKSRefresh() {
+	if s.JWTService == nil {
+		return
+	}
+	go s.JWTService.Run(context.Background())
+}
+
+// startClickEventIngester launches the click-event ingester's batching/flush
+// loop; TrackClick only ever enqueues into it. Shutdown is wired separately
+// so main.go can drain it gracefully alongside the HTTP server.
+func (s *Server) startClickEventIngester() {
+	if s.ClickEventIngester == nil {
+		return
+	}
+	go s.ClickEventIngester.Run(context.Background())
+}
+
+// startClickEventConsumer launches the click-event consumer's queue drain
+// loop; it enriches and hands every delivery off to ClickEventIngester.
+// Shutdown is wired separately so main.go can drain it gracefully alongside
+// the HTTP server.
+func (s *Server) startClickEventConsumer() {
+	if s.ClickEventConsumer == nil {
+		return
+	}
+	go s.ClickEventConsumer.Run(context.Background())
+}
+
+// startHealthService launches HealthService's background dependency-ping
+// loop backing /readyz; main.go calls HealthService.Shutdown directly (no
+// ctx needed) at the start of graceful shutdown, ahead of this loop ever
+// ticking again.
+func (s *Server) startHealthService() {
+	if s.HealthService == nil {
+		return
+	}
+	go s.HealthService.Run(context.Background())
+}
+
+// startGeoIPUpdater launches the daily GeoIP database download/refresh loop,
+// gated on Service.GeoIPAutoUpdate so deployments using the ipapi provider
+// (or managing the database file themselves) don't pay for it.
+func (s *Server) startGeoIPUpdater() {
+	if s.GeoIPUpdater == nil || !s.Config.Service.GeoIPAutoUpdate {
+		return
+	}
+	go s.GeoIPUpdater.Run(context.Background())
+}
+
 func (s *Server) GetRouter() *gin.Engine {
 	return s.Router
 }
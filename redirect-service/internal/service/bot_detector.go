@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/hoggir/re-path/redirect-service/internal/config"
+	"github.com/hoggir/re-path/redirect-service/internal/database"
+	"github.com/hoggir/re-path/redirect-service/internal/domain"
+	"github.com/hoggir/re-path/redirect-service/internal/logger"
+	"github.com/mileusna/useragent"
+	"github.com/redis/rueidis"
+)
+
+// headlessUASignatures are substrings that mark a request as coming from an
+// automated browser, as distinct from useragent.Parse's narrower Bot flag
+// (which mostly catches declared crawlers, not headless/scripted clients).
+var headlessUASignatures = []string{"headlesschrome", "phantomjs", "selenium", "puppeteer", "playwright"}
+
+// botRateScript increments a fixed-window counter for key and reports the
+// post-increment count, setting the window's TTL only on the first hit so
+// the count resets cleanly every RateWindowSeconds instead of needing a
+// separate cleanup job.
+var botRateScript = rueidis.NewLuaScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("EXPIRE", KEYS[1], ARGV[1])
+end
+return count
+`)
+
+// BotDetector scores one click across several independent signals instead
+// of trusting useragent.Parse's Bot flag alone: known UA bot/headless
+// markers, an empty UA, known datacenter/crawler IP ranges, and the click
+// rate from the same IP. Every signal that fires adds to the result's
+// Confidence and names itself in Reasons, so analytic-service can audit or
+// re-weight a flagged click rather than only seeing a boolean.
+type BotDetector interface {
+	Detect(ctx context.Context, metadata domain.ClickMetadata, ua useragent.UserAgent) domain.BotDetectionResult
+}
+
+type botDetector struct {
+	redis     *database.Redis
+	cacheKeys *CacheKeyGenerator
+	ranges    []datacenterRange
+	cfg       *config.Config
+	logger    logger.Logger
+}
+
+type datacenterRange struct {
+	network *net.IPNet
+	label   string
+}
+
+type datacenterRangeEntry struct {
+	CIDR  string `json:"cidr"`
+	Label string `json:"label"`
+}
+
+// NewBotDetector loads BotDetection.DatacenterRangesPath, if configured.
+// A missing or invalid file only disables the datacenter-range signal; it
+// never prevents the service from starting, the same tolerance GeoIPService
+// gives a misconfigured local database path.
+func NewBotDetector(redis *database.Redis, cacheKeys *CacheKeyGenerator, cfg *config.Config, log logger.Logger) BotDetector {
+	ranges, err := loadDatacenterRanges(cfg.BotDetection.DatacenterRangesPath)
+	if err != nil {
+		log.Warn("failed to load datacenter IP ranges, datacenter heuristic disabled", "path", cfg.BotDetection.DatacenterRangesPath, "error", err)
+	}
+
+	return &botDetector{
+		redis:     redis,
+		cacheKeys: cacheKeys,
+		ranges:    ranges,
+		cfg:       cfg,
+		logger:    log,
+	}
+}
+
+func loadDatacenterRanges(path string) ([]datacenterRange, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []datacenterRangeEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+
+	ranges := make([]datacenterRange, 0, len(entries))
+	for _, entry := range entries {
+		_, network, err := net.ParseCIDR(entry.CIDR)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q: %w", entry.CIDR, err)
+		}
+		ranges = append(ranges, datacenterRange{network: network, label: entry.Label})
+	}
+
+	return ranges, nil
+}
+
+func (d *botDetector) Detect(ctx context.Context, metadata domain.ClickMetadata, ua useragent.UserAgent) domain.BotDetectionResult {
+	if !d.cfg.BotDetection.Enabled {
+		return domain.BotDetectionResult{}
+	}
+
+	var confidence float64
+	var reasons []string
+
+	if ua.Bot {
+		confidence += 0.9
+		reasons = append(reasons, "ua_bot_signature")
+	}
+
+	if metadata.UserAgent == "" {
+		confidence += 0.4
+		reasons = append(reasons, "empty_user_agent")
+	} else if sig, ok := matchHeadlessSignature(metadata.UserAgent); ok {
+		confidence += 0.6
+		reasons = append(reasons, "headless_browser_ua:"+sig)
+	}
+
+	if label, ok := d.matchDatacenter(metadata.ClientIP); ok {
+		confidence += 0.5
+		reasons = append(reasons, "datacenter_ip:"+label)
+	}
+
+	if d.exceedsClickRate(ctx, metadata.ClientIP) {
+		confidence += 0.5
+		reasons = append(reasons, "high_click_rate")
+	}
+
+	if confidence > 1.0 {
+		confidence = 1.0
+	}
+
+	return domain.BotDetectionResult{
+		IsBot:      confidence >= d.cfg.BotDetection.ConfidenceThreshold,
+		Confidence: confidence,
+		Reasons:    reasons,
+	}
+}
+
+func matchHeadlessSignature(userAgent string) (string, bool) {
+	lowered := strings.ToLower(userAgent)
+	for _, sig := range headlessUASignatures {
+		if strings.Contains(lowered, sig) {
+			return sig, true
+		}
+	}
+	return "", false
+}
+
+func (d *botDetector) matchDatacenter(ip string) (string, bool) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return "", false
+	}
+	for _, r := range d.ranges {
+		if r.network.Contains(parsed) {
+			return r.label, true
+		}
+	}
+	return "", false
+}
+
+// exceedsClickRate reports whether ip has already produced more than
+// RateThreshold clicks in the current RateWindowSeconds window. A Redis
+// failure only disables this one signal for this click; it never blocks
+// enrichment.
+func (d *botDetector) exceedsClickRate(ctx context.Context, ip string) bool {
+	if d.cfg.BotDetection.RateThreshold <= 0 {
+		return false
+	}
+
+	key := d.cacheKeys.BotRate(hashIPAddress(ip))
+	resp := botRateScript.Exec(ctx, d.redis.Client, []string{key}, []string{
+		strconv.Itoa(d.cfg.BotDetection.RateWindowSeconds),
+	})
+	if resp.Error() != nil {
+		d.logger.WarnContext(ctx, "bot detector rate check failed", "ip", ip, "error", resp.Error())
+		return false
+	}
+
+	count, err := resp.ToInt64()
+	if err != nil {
+		d.logger.WarnContext(ctx, "bot detector rate check failed", "ip", ip, "error", err)
+		return false
+	}
+
+	return count > int64(d.cfg.BotDetection.RateThreshold)
+}
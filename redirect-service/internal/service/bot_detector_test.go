@@ -0,0 +1,44 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMatchHeadlessSignature(t *testing.T) {
+	cases := map[string]bool{
+		"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 HeadlessChrome/120.0":     true,
+		"Mozilla/5.0 (Macintosh) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0": false,
+		"PhantomJS/2.1.1": true,
+		"":                false,
+	}
+
+	for ua, want := range cases {
+		_, got := matchHeadlessSignature(ua)
+		if got != want {
+			t.Errorf("matchHeadlessSignature(%q) = %v, want %v", ua, got, want)
+		}
+	}
+}
+
+func TestLoadDatacenterRanges_EmptyPathDisablesSignal(t *testing.T) {
+	ranges, err := loadDatacenterRanges("")
+	if err != nil {
+		t.Fatalf("loadDatacenterRanges(\"\") returned error: %v", err)
+	}
+	if ranges != nil {
+		t.Fatalf("loadDatacenterRanges(\"\") = %v, want nil", ranges)
+	}
+}
+
+func TestLoadDatacenterRanges_InvalidCIDR(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ranges.json")
+	if err := os.WriteFile(path, []byte(`[{"cidr": "not-a-cidr", "label": "test"}]`), 0o644); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+
+	if _, err := loadDatacenterRanges(path); err == nil {
+		t.Fatal("loadDatacenterRanges() with an invalid CIDR = nil error, want non-nil")
+	}
+}
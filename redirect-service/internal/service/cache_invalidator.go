@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hoggir/re-path/redirect-service/internal/database"
+	"github.com/hoggir/re-path/redirect-service/internal/domain"
+	"github.com/hoggir/re-path/redirect-service/internal/logger"
+	"github.com/redis/rueidis"
+)
+
+// cacheOp is the write that provoked an invalidation message. Only "delete"
+// is published today - PublishDeleted is CacheInvalidator's only producer.
+type cacheOp string
+
+const (
+	cacheOpDelete cacheOp = "delete"
+)
+
+// invalidationMessage is the wire schema for the cross-replica cache bus.
+// Prefix lets a single channel carry events for every CacheKeyGenerator
+// namespace (url, dashboard, ...) so handle can dispatch without needing a
+// channel per key type, and OriginID lets a node ignore its own broadcasts.
+type invalidationMessage struct {
+	Key      string  `json:"key"`
+	Prefix   string  `json:"prefix"`
+	Op       cacheOp `json:"op"`
+	TS       int64   `json:"ts"`
+	OriginID string  `json:"origin_id"`
+}
+
+// CacheInvalidator keeps replica-local caches honest. It publishes
+// invalidation events whenever cached state changes and subscribes on
+// startup to drop matching local entries - both the url
+// side-cache and CacheService's GetOrLoad L1 - so other replicas don't keep
+// serving stale data until the TTL lapses.
+type CacheInvalidator interface {
+	// PublishDeleted announces that shortCode was deactivated or removed.
+	PublishDeleted(ctx context.Context, shortCode string) error
+	// Local returns the replica-local side-cache entry for shortCode, if any.
+	Local(shortCode string) (*domain.FindByShortCode, bool)
+	// Run subscribes to the invalidation channel and blocks until ctx is done.
+	Run(ctx context.Context)
+}
+
+type cacheInvalidator struct {
+	redis     *database.Redis
+	cache     CacheService
+	cacheKeys *CacheKeyGenerator
+	local     *lruCache
+	channel   string
+	origin    string
+	logger    logger.Logger
+
+	coalesceMu     sync.Mutex
+	coalesceTimers map[string]*time.Timer
+	coalesceWindow time.Duration
+}
+
+func NewCacheInvalidator(
+	redis *database.Redis,
+	cache CacheService,
+	cacheKeys *CacheKeyGenerator,
+	log logger.Logger,
+) CacheInvalidator {
+	return &cacheInvalidator{
+		redis:          redis,
+		cache:          cache,
+		cacheKeys:      cacheKeys,
+		local:          newLRUCache(1024),
+		channel:        fmt.Sprintf("%s:cache:invalidate", cacheKeys.Prefix()),
+		origin:         uuid.New().String(),
+		logger:         log,
+		coalesceTimers: make(map[string]*time.Timer),
+		coalesceWindow: 50 * time.Millisecond,
+	}
+}
+
+func (i *cacheInvalidator) PublishDeleted(ctx context.Context, shortCode string) error {
+	return i.publish(ctx, i.cacheKeys.URL(shortCode), "url", cacheOpDelete)
+}
+
+func (i *cacheInvalidator) publish(ctx context.Context, key, prefix string, op cacheOp) error {
+	msg := invalidationMessage{
+		Key:      key,
+		Prefix:   prefix,
+		Op:       op,
+		TS:       time.Now().Unix(),
+		OriginID: i.origin,
+	}
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshal invalidation message: %w", err)
+	}
+
+	cmd := i.redis.Client.B().Publish().Channel(i.channel).Message(string(payload)).Build()
+	if err := i.redis.Client.Do(ctx, cmd).Error(); err != nil {
+		return domain.ErrCacheError.
+			WithContext("key", key).
+			WithContext("operation", "PublishInvalidation").
+			Wrap(err)
+	}
+
+	// Apply locally too - our own Run() loop will ignore the echo by
+	// OriginID, but the publisher shouldn't wait for the round trip to see
+	// fresh state.
+	i.cache.InvalidateLocal(key)
+	if prefix == "url" {
+		i.local.Delete(i.cacheKeys.ShortCodeFromURLKey(key))
+	}
+
+	return nil
+}
+
+func (i *cacheInvalidator) Local(shortCode string) (*domain.FindByShortCode, bool) {
+	v, ok := i.local.Get(shortCode)
+	if !ok {
+		return nil, false
+	}
+	url, ok := v.(*domain.FindByShortCode)
+	return url, ok
+}
+
+func (i *cacheInvalidator) Run(ctx context.Context) {
+	for {
+		err := i.redis.Client.Receive(ctx, i.redis.Client.B().Subscribe().Channel(i.channel).Build(), func(msg rueidis.PubSubMessage) error {
+			i.handle(ctx, msg.Message)
+			return nil
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			i.logger.WarnContext(ctx, "cache invalidation subscription dropped, retrying", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+func (i *cacheInvalidator) handle(ctx context.Context, payload string) {
+	var msg invalidationMessage
+	if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+		i.logger.WarnContext(ctx, "failed to decode invalidation message", "error", err)
+		return
+	}
+
+	if msg.OriginID == i.origin {
+		// Our own echo - we already applied it locally when we published.
+		return
+	}
+
+	i.coalesce(msg.Key, func() {
+		i.apply(ctx, msg)
+	})
+}
+
+// coalesce collapses a burst of invalidations for the same key into a single
+// apply, run coalesceWindow after the last message for that key arrives.
+func (i *cacheInvalidator) coalesce(key string, fn func()) {
+	i.coalesceMu.Lock()
+	defer i.coalesceMu.Unlock()
+
+	if t, ok := i.coalesceTimers[key]; ok {
+		t.Stop()
+	}
+
+	i.coalesceTimers[key] = time.AfterFunc(i.coalesceWindow, func() {
+		i.coalesceMu.Lock()
+		delete(i.coalesceTimers, key)
+		i.coalesceMu.Unlock()
+		fn()
+	})
+}
+
+// apply drops msg.Key from both the GetOrLoad L1 tier and Redis on every
+// prefix, plus the url-specific side-cache entry for the "url" prefix.
+func (i *cacheInvalidator) apply(ctx context.Context, msg invalidationMessage) {
+	i.cache.InvalidateLocal(msg.Key)
+
+	if err := i.cache.Delete(ctx, msg.Key); err != nil {
+		i.logger.WarnContext(ctx, "failed to delete cache entry for invalidation", "key", msg.Key, "error", err)
+	}
+
+	if msg.Prefix != "url" {
+		return
+	}
+
+	i.local.Delete(i.cacheKeys.ShortCodeFromURLKey(msg.Key))
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/hoggir/re-path/redirect-service/internal/config"
 )
@@ -20,6 +21,14 @@ func NewCacheKeyGenerator(cfg *config.Config) *CacheKeyGenerator {
 	}
 }
 
+// Prefix returns the deployment-scoped namespace every key this generator
+// produces is rooted under, so callers building their own Redis channel or
+// pattern names (e.g. CacheInvalidator) don't cross-talk with another
+// deployment sharing the same Redis cluster.
+func (g *CacheKeyGenerator) Prefix() string {
+	return g.prefix
+}
+
 func (g *CacheKeyGenerator) URL(shortCode string) string {
 	return fmt.Sprintf("%s:url:%s", g.prefix, shortCode)
 }
@@ -35,3 +44,44 @@ func (g *CacheKeyGenerator) GeoIP(ip string) string {
 func (g *CacheKeyGenerator) DashboardInvalidationFlag(userID int) string {
 	return fmt.Sprintf("%s:dashboard_invalid:%d", g.prefix, userID)
 }
+
+func (g *CacheKeyGenerator) RefreshToken(jti string) string {
+	return fmt.Sprintf("%s:refresh:%s", g.prefix, jti)
+}
+
+// RevokedToken is set for one explicitly-revoked token jti - currently only
+// written by JWTService.Logout for the access token presented to it - so
+// that exact token stops validating immediately, without waiting on
+// TokenVersion to propagate.
+func (g *CacheKeyGenerator) RevokedToken(jti string) string {
+	return fmt.Sprintf("%s:revoked:%s", g.prefix, jti)
+}
+
+// TokenVersion holds the counter compared against a token's token_version
+// claim. Bumping it invalidates every access token already issued to userID
+// at once, without the revoker needing to know any of their jtis.
+func (g *CacheKeyGenerator) TokenVersion(userID int) string {
+	return fmt.Sprintf("%s:token_version:%d", g.prefix, userID)
+}
+
+// UserRefreshTokens is a set of jtis for every refresh token currently
+// outstanding for userID, so Logout can find and revoke them all.
+func (g *CacheKeyGenerator) UserRefreshTokens(userID int) string {
+	return fmt.Sprintf("%s:refresh_set:%d", g.prefix, userID)
+}
+
+// BotRate is the fixed-window counter BotDetector increments per IP hash to
+// spot an unusually high click rate from the same client.
+func (g *CacheKeyGenerator) BotRate(ipHash string) string {
+	return fmt.Sprintf("%s:bot_rate:%s", g.prefix, ipHash)
+}
+
+// ShortCodeFromURLKey reverses URL, extracting the shortCode back out of a
+// cache key produced by it. Returns "" if key isn't in that format.
+func (g *CacheKeyGenerator) ShortCodeFromURLKey(key string) string {
+	urlPrefix := fmt.Sprintf("%s:url:", g.prefix)
+	if !strings.HasPrefix(key, urlPrefix) {
+		return ""
+	}
+	return strings.TrimPrefix(key, urlPrefix)
+}
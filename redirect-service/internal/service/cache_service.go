@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -10,22 +11,58 @@ import (
 	"github.com/hoggir/re-path/redirect-service/internal/database"
 	"github.com/hoggir/re-path/redirect-service/internal/domain"
 	"github.com/hoggir/re-path/redirect-service/internal/logger"
-	"github.com/redis/go-redis/v9"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/rueidis"
+	"golang.org/x/sync/singleflight"
 )
 
+// cacheGetOrLoadTotal partitions every GetOrLoad call by how it was served:
+// "l1_hit" and "l2_hit" never touched loader, "miss" and "coalesced" both
+// called it (the latter joined an in-flight call instead of starting its
+// own), and "stale_served" returned an expired-but-present value while a
+// refresh ran in the background.
+var cacheGetOrLoadTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "repath_cache_getorload_total",
+	Help: "Total CacheService.GetOrLoad calls, partitioned by how the result was served.",
+}, []string{"result"})
+
 type CacheService interface {
 	Get(ctx context.Context, key string, dest interface{}) error
+	GetCached(ctx context.Context, key string, dest interface{}, localTTL time.Duration) error
 	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
 	Delete(ctx context.Context, key string) error
 	Exists(ctx context.Context, key string) (bool, error)
 	RefreshTTL(ctx context.Context, key string, ttl time.Duration) error
 	SetInvalidationFlag(ctx context.Context, key string, ttl time.Duration) error
+	// SetNX sets key only if it doesn't already exist, returning whether this
+	// call was the one that set it. Used for short-lived distributed locks.
+	SetNX(ctx context.Context, key string, value string, ttl time.Duration) (bool, error)
+	// ScanKeys walks the keyspace for keys matching pattern via SCAN, never
+	// blocking Redis the way KEYS would.
+	ScanKeys(ctx context.Context, pattern string) ([]string, error)
+	// GetOrLoad is a two-tier read-through cache for hot keys: L1 is a
+	// bounded in-process LRU, L2 is Redis, and a singleflight.Group keyed by
+	// key coalesces concurrent loader calls so a burst of requests for the
+	// same key costs exactly one loader invocation. ttl is the hard expiry;
+	// once config.Redis.StaleWhileRevalidateRatio has elapsed, GetOrLoad
+	// serves the stale value immediately and refreshes it in the background.
+	// dest must be a pointer, as with Get.
+	GetOrLoad(ctx context.Context, key string, ttl time.Duration, loader func(ctx context.Context) (interface{}, error), dest interface{}) error
+	// InvalidateLocal drops key from the GetOrLoad L1 tier only; it does not
+	// touch Redis. CacheInvalidator calls this when a cross-replica bus
+	// message arrives so a stale value already sitting in this process's L1
+	// doesn't outlive the write that just happened on another replica.
+	InvalidateLocal(key string)
 }
 
 type cacheService struct {
 	redis  *database.Redis
 	config *config.Config
 	logger logger.Logger
+
+	l1 *ttlLRUCache
+	sf singleflight.Group
 }
 
 func NewCacheService(redis *database.Redis, cfg *config.Config, log logger.Logger) CacheService {
@@ -33,13 +70,23 @@ func NewCacheService(redis *database.Redis, cfg *config.Config, log logger.Logge
 		redis:  redis,
 		config: cfg,
 		logger: log,
+		l1:     newTTLLRUCache(cfg.Redis.L1Capacity),
 	}
 }
 
+// cacheEnvelope is GetOrLoad's L2 wire format: unlike Get/Set, which store
+// dest's JSON encoding directly, GetOrLoad needs room for the soft/hard
+// expiry pair that drives stale-while-revalidate.
+type cacheEnvelope struct {
+	Value      json.RawMessage `json:"value"`
+	SoftExpiry time.Time       `json:"soft_expiry"`
+	HardExpiry time.Time       `json:"hard_expiry"`
+}
+
 func (s *cacheService) Get(ctx context.Context, key string, dest interface{}) error {
-	data, err := s.redis.Client.Get(ctx, key).Result()
+	data, err := s.redis.Client.Do(ctx, s.redis.Client.B().Get().Key(key).Build()).ToString()
 	if err != nil {
-		if err == redis.Nil {
+		if rueidis.IsRedisNil(err) {
 			// Cache miss is not really an error, just return a specific error
 			return fmt.Errorf("cache miss: key %s not found", key)
 		}
@@ -59,6 +106,33 @@ func (s *cacheService) Get(ctx context.Context, key string, dest interface{}) er
 	return nil
 }
 
+// GetCached serves a lookup from the process-local RESP3 client-side cache when
+// possible. rueidis tracks the key via CLIENT TRACKING and Redis pushes an
+// invalidation message when it changes, so repeat hits within localTTL cost
+// zero round trips.
+func (s *cacheService) GetCached(ctx context.Context, key string, dest interface{}, localTTL time.Duration) error {
+	cmd := s.redis.Client.B().Get().Key(key).Cache()
+	data, err := s.redis.Client.DoCache(ctx, cmd, localTTL).ToString()
+	if err != nil {
+		if rueidis.IsRedisNil(err) {
+			return fmt.Errorf("cache miss: key %s not found", key)
+		}
+		return domain.ErrCacheError.
+			WithContext("key", key).
+			WithContext("operation", "GetCached").
+			Wrap(err)
+	}
+
+	if err := json.Unmarshal([]byte(data), dest); err != nil {
+		return domain.ErrCacheError.
+			WithContext("key", key).
+			WithContext("operation", "Unmarshal").
+			Wrap(err)
+	}
+
+	return nil
+}
+
 func (s *cacheService) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
 	data, err := json.Marshal(value)
 	if err != nil {
@@ -68,7 +142,8 @@ func (s *cacheService) Set(ctx context.Context, key string, value interface{}, t
 			Wrap(err)
 	}
 
-	if err := s.redis.Client.Set(ctx, key, data, ttl).Err(); err != nil {
+	cmd := s.redis.Client.B().Set().Key(key).Value(rueidis.BinaryString(data)).Ex(ttl).Build()
+	if err := s.redis.Client.Do(ctx, cmd).Error(); err != nil {
 		return domain.ErrCacheError.
 			WithContext("key", key).
 			WithContext("operation", "Set").
@@ -80,7 +155,8 @@ func (s *cacheService) Set(ctx context.Context, key string, value interface{}, t
 }
 
 func (s *cacheService) Delete(ctx context.Context, key string) error {
-	if err := s.redis.Client.Del(ctx, key).Err(); err != nil {
+	cmd := s.redis.Client.B().Del().Key(key).Build()
+	if err := s.redis.Client.Do(ctx, cmd).Error(); err != nil {
 		return domain.ErrCacheError.
 			WithContext("key", key).
 			WithContext("operation", "Delete").
@@ -92,7 +168,8 @@ func (s *cacheService) Delete(ctx context.Context, key string) error {
 }
 
 func (s *cacheService) Exists(ctx context.Context, key string) (bool, error) {
-	exists, err := s.redis.Client.Exists(ctx, key).Result()
+	cmd := s.redis.Client.B().Exists().Key(key).Build()
+	exists, err := s.redis.Client.Do(ctx, cmd).ToInt64()
 	if err != nil {
 		return false, domain.ErrCacheError.
 			WithContext("key", key).
@@ -104,7 +181,8 @@ func (s *cacheService) Exists(ctx context.Context, key string) (bool, error) {
 }
 
 func (s *cacheService) RefreshTTL(ctx context.Context, key string, ttl time.Duration) error {
-	if err := s.redis.Client.Expire(ctx, key, ttl).Err(); err != nil {
+	cmd := s.redis.Client.B().Expire().Key(key).Seconds(int64(ttl.Seconds())).Build()
+	if err := s.redis.Client.Do(ctx, cmd).Error(); err != nil {
 		s.logger.WarnContext(ctx, "failed to refresh cache TTL", "key", key, "error", err)
 		return domain.ErrCacheError.
 			WithContext("key", key).
@@ -115,8 +193,11 @@ func (s *cacheService) RefreshTTL(ctx context.Context, key string, ttl time.Dura
 	return nil
 }
 
+// SetInvalidationFlag writes a plain (non-cached) key so that RESP3 client-side
+// tracking invalidation still propagates it correctly to other replicas.
 func (s *cacheService) SetInvalidationFlag(ctx context.Context, key string, ttl time.Duration) error {
-	if err := s.redis.Client.Set(ctx, key, "1", ttl).Err(); err != nil {
+	cmd := s.redis.Client.B().Set().Key(key).Value("1").Ex(ttl).Build()
+	if err := s.redis.Client.Do(ctx, cmd).Error(); err != nil {
 		return domain.ErrCacheError.
 			WithContext("key", key).
 			WithContext("operation", "SetInvalidationFlag").
@@ -125,3 +206,178 @@ func (s *cacheService) SetInvalidationFlag(ctx context.Context, key string, ttl
 
 	return nil
 }
+
+func (s *cacheService) SetNX(ctx context.Context, key string, value string, ttl time.Duration) (bool, error) {
+	cmd := s.redis.Client.B().Set().Key(key).Value(value).Nx().Ex(ttl).Build()
+	resp := s.redis.Client.Do(ctx, cmd)
+	if resp.Error() != nil {
+		if rueidis.IsRedisNil(resp.Error()) {
+			// NX lost the race - another replica holds the lock.
+			return false, nil
+		}
+		return false, domain.ErrCacheError.
+			WithContext("key", key).
+			WithContext("operation", "SetNX").
+			Wrap(resp.Error())
+	}
+
+	return true, nil
+}
+
+func (s *cacheService) ScanKeys(ctx context.Context, pattern string) ([]string, error) {
+	var keys []string
+	cursor := uint64(0)
+
+	for {
+		cmd := s.redis.Client.B().Scan().Cursor(cursor).Match(pattern).Count(200).Build()
+		entry, err := s.redis.Client.Do(ctx, cmd).AsScanEntry()
+		if err != nil {
+			return nil, domain.ErrCacheError.
+				WithContext("pattern", pattern).
+				WithContext("operation", "ScanKeys").
+				Wrap(err)
+		}
+
+		keys = append(keys, entry.Elements...)
+		cursor = entry.Cursor
+
+		if cursor == 0 {
+			break
+		}
+	}
+
+	return keys, nil
+}
+
+func (s *cacheService) GetOrLoad(ctx context.Context, key string, ttl time.Duration, loader func(ctx context.Context) (interface{}, error), dest interface{}) error {
+	if raw, ok := s.l1.Get(key); ok {
+		cacheGetOrLoadTotal.WithLabelValues("l1_hit").Inc()
+		return json.Unmarshal(raw, dest)
+	}
+
+	env, err := s.getEnvelope(ctx, key)
+	if err == nil {
+		if time.Now().After(env.SoftExpiry) {
+			cacheGetOrLoadTotal.WithLabelValues("stale_served").Inc()
+			go s.refreshInBackground(key, ttl, loader)
+		} else {
+			cacheGetOrLoadTotal.WithLabelValues("l2_hit").Inc()
+			s.l1.Set(key, env.Value, time.Until(env.SoftExpiry))
+		}
+		return json.Unmarshal(env.Value, dest)
+	}
+
+	raw, shared, sfErr := s.sf.Do(key, func() (interface{}, error) {
+		return s.loadAndStore(ctx, key, ttl, loader)
+	})
+	if sfErr != nil {
+		return sfErr
+	}
+
+	if shared {
+		cacheGetOrLoadTotal.WithLabelValues("coalesced").Inc()
+	} else {
+		cacheGetOrLoadTotal.WithLabelValues("miss").Inc()
+	}
+
+	return json.Unmarshal(raw.([]byte), dest)
+}
+
+func (s *cacheService) InvalidateLocal(key string) {
+	s.l1.Delete(key)
+}
+
+// getEnvelope reads and decodes GetOrLoad's L2 envelope for key, returning
+// the same "cache miss" error as Get when it isn't present.
+func (s *cacheService) getEnvelope(ctx context.Context, key string) (*cacheEnvelope, error) {
+	data, err := s.redis.Client.Do(ctx, s.redis.Client.B().Get().Key(key).Build()).ToString()
+	if err != nil {
+		if rueidis.IsRedisNil(err) {
+			return nil, fmt.Errorf("cache miss: key %s not found", key)
+		}
+		return nil, domain.ErrCacheError.
+			WithContext("key", key).
+			WithContext("operation", "GetOrLoad").
+			Wrap(err)
+	}
+
+	var env cacheEnvelope
+	if err := json.Unmarshal([]byte(data), &env); err != nil {
+		return nil, domain.ErrCacheError.
+			WithContext("key", key).
+			WithContext("operation", "GetOrLoad").
+			Wrap(err)
+	}
+
+	return &env, nil
+}
+
+// loadAndStore calls loader, writes the result to Redis as a soft/hard
+// expiry envelope and to L1, and returns the value's JSON encoding.
+func (s *cacheService) loadAndStore(ctx context.Context, key string, ttl time.Duration, loader func(ctx context.Context) (interface{}, error)) ([]byte, error) {
+	value, err := loader(ctx)
+	if err != nil {
+		// loader is caller-supplied (e.g. URLRepository.FindByShortCode) and
+		// may already be a domain error - ErrURLNotFound/Inactive/Expired and
+		// their HTTP statuses - which dto.HandleError needs to see unwrapped.
+		// Only a genuine cache-layer failure below gets wrapped as
+		// ErrCacheError.
+		var appErr *domain.AppError
+		if errors.As(err, &appErr) {
+			return nil, appErr
+		}
+		return nil, domain.ErrCacheError.
+			WithContext("key", key).
+			WithContext("operation", "GetOrLoad").
+			Wrap(err)
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, domain.ErrCacheError.
+			WithContext("key", key).
+			WithContext("operation", "Marshal").
+			Wrap(err)
+	}
+
+	now := time.Now()
+	softTTL := time.Duration(float64(ttl) * s.config.Redis.StaleWhileRevalidateRatio)
+	if s.config.Redis.StaleWhileRevalidateRatio <= 0 {
+		softTTL = ttl
+	}
+	env := cacheEnvelope{
+		Value:      raw,
+		SoftExpiry: now.Add(softTTL),
+		HardExpiry: now.Add(ttl),
+	}
+
+	envData, err := json.Marshal(env)
+	if err != nil {
+		return nil, domain.ErrCacheError.
+			WithContext("key", key).
+			WithContext("operation", "Marshal").
+			Wrap(err)
+	}
+
+	cmd := s.redis.Client.B().Set().Key(key).Value(rueidis.BinaryString(envData)).Ex(ttl).Build()
+	if err := s.redis.Client.Do(ctx, cmd).Error(); err != nil {
+		s.logger.WarnContext(ctx, "failed to cache GetOrLoad result", "key", key, "error", err)
+	}
+
+	s.l1.Set(key, raw, softTTL)
+	return raw, nil
+}
+
+// refreshInBackground reloads a stale key through the same singleflight
+// group GetOrLoad's miss path uses, so a refresh already in flight for key
+// is never started twice.
+func (s *cacheService) refreshInBackground(key string, ttl time.Duration, loader func(ctx context.Context) (interface{}, error)) {
+	ctx, cancel := context.WithTimeout(context.Background(), ttl)
+	defer cancel()
+
+	if _, _, err := s.sf.Do(key, func() (interface{}, error) {
+		return s.loadAndStore(ctx, key, ttl, loader)
+	}); err != nil {
+		s.logger.WarnContext(ctx, "background refresh failed", "key", key, "error", err)
+	}
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/hoggir/re-path/redirect-service/internal/config"
+	"github.com/hoggir/re-path/redirect-service/internal/logger"
+	"github.com/hoggir/re-path/redirect-service/internal/repository"
+)
+
+// ClickCountBatcher coalesces per-shortCode click-count increments behind a
+// bounded queue, so a viral link getting hammered turns into one bulk $inc
+// every Service.ClickBatchWindow instead of one write per redirect. Enqueue
+// is the backpressure point: once the queue is full, callers block on it (or
+// on their own ctx) rather than piling up unbounded goroutines.
+type ClickCountBatcher interface {
+	// Enqueue records one click for shortCode, blocking if the queue is full
+	// until space frees up or ctx is done.
+	Enqueue(ctx context.Context, shortCode string) error
+	// Run drains the queue and flushes accumulated deltas on
+	// Service.ClickBatchWindow or once Service.ClickBatchMaxSize distinct
+	// short codes are pending, until ctx is done.
+	Run(ctx context.Context)
+}
+
+type clickCountBatcher struct {
+	urlRepo repository.URLRepository
+	config  *config.Config
+	logger  logger.Logger
+
+	queue   chan string
+	pending map[string]int
+}
+
+func NewClickCountBatcher(urlRepo repository.URLRepository, cfg *config.Config, log logger.Logger) ClickCountBatcher {
+	return &clickCountBatcher{
+		urlRepo: urlRepo,
+		config:  cfg,
+		logger:  log,
+		queue:   make(chan string, cfg.Service.ClickBatchMaxSize*4),
+		pending: make(map[string]int),
+	}
+}
+
+func (b *clickCountBatcher) Enqueue(ctx context.Context, shortCode string) error {
+	select {
+	case b.queue <- shortCode:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *clickCountBatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(b.config.Service.ClickBatchWindow)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			b.drainQueue()
+			b.flush(context.Background())
+			return
+		case shortCode := <-b.queue:
+			b.pending[shortCode]++
+			if len(b.pending) >= b.config.Service.ClickBatchMaxSize {
+				b.flush(ctx)
+			}
+		case <-ticker.C:
+			b.flush(ctx)
+		}
+	}
+}
+
+// drainQueue pulls every shortCode already sent into b.queue (but not yet
+// picked up by Run's select) into b.pending, so the shutdown flush doesn't
+// drop clicks Enqueue already reported as recorded. It never blocks: once the
+// queue reads empty, whatever's left was never enqueued in the first place.
+func (b *clickCountBatcher) drainQueue() {
+	for {
+		select {
+		case shortCode := <-b.queue:
+			b.pending[shortCode]++
+		default:
+			return
+		}
+	}
+}
+
+func (b *clickCountBatcher) flush(ctx context.Context) {
+	if len(b.pending) == 0 {
+		return
+	}
+
+	deltas := b.pending
+	b.pending = make(map[string]int)
+
+	if err := b.urlRepo.IncrementClickCounts(ctx, deltas); err != nil {
+		b.logger.WarnContext(ctx, "failed to flush click count batch", "batchSize", len(deltas), "error", err)
+	}
+}
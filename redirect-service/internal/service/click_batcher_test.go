@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hoggir/re-path/redirect-service/internal/config"
+	"github.com/hoggir/re-path/redirect-service/internal/domain"
+)
+
+// fakeURLRepository records IncrementClickCounts calls; every other
+// URLRepository method is unused by ClickCountBatcher and panics if hit.
+type fakeURLRepository struct {
+	mu    sync.Mutex
+	calls []map[string]int
+}
+
+func (f *fakeURLRepository) FindByShortCode(ctx context.Context, shortCode string) (*domain.FindByShortCode, error) {
+	panic("not used by ClickCountBatcher")
+}
+
+func (f *fakeURLRepository) IncrementClickCount(ctx context.Context, shortCode string) error {
+	panic("not used by ClickCountBatcher")
+}
+
+func (f *fakeURLRepository) IncrementClickCounts(ctx context.Context, deltas map[string]int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	cp := make(map[string]int, len(deltas))
+	for k, v := range deltas {
+		cp[k] = v
+	}
+	f.calls = append(f.calls, cp)
+	return nil
+}
+
+func (f *fakeURLRepository) FindExpired(ctx context.Context, limit int64) ([]domain.ExpiredURLRef, error) {
+	panic("not used by ClickCountBatcher")
+}
+
+func (f *fakeURLRepository) DeactivateByShortCode(ctx context.Context, shortCode string) error {
+	panic("not used by ClickCountBatcher")
+}
+
+func (f *fakeURLRepository) DeleteByShortCode(ctx context.Context, shortCode string) error {
+	panic("not used by ClickCountBatcher")
+}
+
+func (f *fakeURLRepository) totalFor(shortCode string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	total := 0
+	for _, deltas := range f.calls {
+		total += deltas[shortCode]
+	}
+	return total
+}
+
+func newTestClickCountBatcher(repo *fakeURLRepository) *clickCountBatcher {
+	cfg := &config.Config{
+		Service: config.ServiceConfig{
+			ClickBatchWindow:  time.Hour,
+			ClickBatchMaxSize: 500,
+		},
+	}
+	return NewClickCountBatcher(repo, cfg, testLogger()).(*clickCountBatcher)
+}
+
+// TestClickCountBatcher_ShutdownDrainsQueuedEnqueues reproduces a shutdown
+// racing Enqueue: shortCodes already sent into b.queue but not yet pulled
+// into b.pending by Run's select must still survive ctx cancellation, since
+// Enqueue already returned nil to its caller for them.
+func TestClickCountBatcher_ShutdownDrainsQueuedEnqueues(t *testing.T) {
+	repo := &fakeURLRepository{}
+	b := newTestClickCountBatcher(repo)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		b.Run(ctx)
+		close(done)
+	}()
+
+	const clicks = 50
+	for i := 0; i < clicks; i++ {
+		if err := b.Enqueue(context.Background(), "abc123"); err != nil {
+			t.Fatalf("Enqueue() error = %v", err)
+		}
+	}
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run() did not return after ctx cancellation")
+	}
+
+	if got := repo.totalFor("abc123"); got != clicks {
+		t.Fatalf("IncrementClickCounts total for abc123 = %d, want %d (clicks dropped on shutdown)", got, clicks)
+	}
+}
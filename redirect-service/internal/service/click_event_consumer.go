@@ -0,0 +1,379 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hoggir/re-path/redirect-service/internal/config"
+	"github.com/hoggir/re-path/redirect-service/internal/database"
+	"github.com/hoggir/re-path/redirect-service/internal/domain"
+	"github.com/hoggir/re-path/redirect-service/internal/eventbus"
+	"github.com/hoggir/re-path/redirect-service/internal/logger"
+	"github.com/hoggir/re-path/redirect-service/internal/tracing"
+	"github.com/mileusna/useragent"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var (
+	clickEventsConsumedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "repath_click_events_consumed_total",
+		Help: "Click events consumed off the click_events queue, by outcome.",
+	}, []string{"outcome"})
+
+	clickEventsRetriedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "repath_click_events_retried_total",
+		Help: "Click events requeued onto click_events.retry for another attempt.",
+	})
+
+	clickEventsDeadLetteredTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "repath_click_events_dead_lettered_total",
+		Help: "Click events that exhausted their retries and were routed to the DLQ.",
+	})
+)
+
+// ClickEventConsumer drains the click_events queue, does the enrichment
+// (UA parse, GeoIP, IP hash, domain extraction) TrackClick used to do
+// synchronously, and hands the result to ClickEventIngester - reusing its
+// existing batching, WAL, and MongoDB/Elasticsearch sinks rather than
+// duplicating them here.
+type ClickEventConsumer interface {
+	// Run consumes deliveries until ctx is done or Shutdown is called; a
+	// closed delivery channel is reopened rather than ending Run.
+	Run(ctx context.Context)
+	// Shutdown cancels the consumer loop and waits for the in-flight
+	// delivery, if any, to finish being acked/nacked.
+	Shutdown(ctx context.Context) error
+}
+
+type clickEventConsumer struct {
+	rabbitmq     *database.RabbitMQ
+	channel      *amqp.Channel
+	ingester     ClickEventIngester
+	geoIPService GeoIPService
+	botDetector  BotDetector
+	cfg          *config.Config
+	logger       logger.Logger
+
+	stopOnce  sync.Once
+	stopCh    chan struct{}
+	stoppedCh chan struct{}
+}
+
+// NewClickEventConsumer builds the click_events consumer selected by
+// cfg.EventBus.Driver: the RabbitMQ-specific implementation below (with its
+// x-death retry counting and DLQ) for "rabbitmq", or a bus-backed consumer
+// for "kafka" - see eventbus_click_event.go. Both satisfy ClickEventConsumer.
+func NewClickEventConsumer(rabbitmq *database.RabbitMQ, bus eventbus.EventBus, ingester ClickEventIngester, geoIPService GeoIPService, botDetector BotDetector, cfg *config.Config, log logger.Logger) (ClickEventConsumer, error) {
+	if eventbus.Driver(cfg.EventBus.Driver) == eventbus.DriverKafka {
+		return newEventBusClickEventConsumer(bus, ingester, geoIPService, botDetector, cfg, log), nil
+	}
+
+	c := &clickEventConsumer{
+		rabbitmq:     rabbitmq,
+		ingester:     ingester,
+		geoIPService: geoIPService,
+		botDetector:  botDetector,
+		cfg:          cfg,
+		logger:       log,
+		stopCh:       make(chan struct{}),
+		stoppedCh:    make(chan struct{}),
+	}
+
+	if err := c.reopen(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// reopen opens a fresh channel off the current connection and applies Qos,
+// replacing c.channel. Called once at construction and again whenever Run's
+// deliveries channel closes underneath it, e.g. after database.RabbitMQ
+// reconnects.
+func (c *clickEventConsumer) reopen() error {
+	ch, err := c.rabbitmq.Connection().Channel()
+	if err != nil {
+		return domain.ErrQueueError.WithContext("operation", "Channel").Wrap(err)
+	}
+
+	if err := ch.Qos(c.cfg.RabbitMQ.ClickConsumerPrefetch, 0, false); err != nil {
+		ch.Close()
+		return domain.ErrQueueError.WithContext("operation", "Qos").Wrap(err)
+	}
+
+	c.channel = ch
+	return nil
+}
+
+// waitBeforeReopen pauses briefly before Run retries reopen(), so a
+// connection that's mid-reconnect (see database.RabbitMQ.supervise) isn't
+// hammered with Channel() calls that are bound to fail until it's back.
+func (c *clickEventConsumer) waitBeforeReopen(ctx context.Context) bool {
+	select {
+	case <-time.After(time.Second):
+		return true
+	case <-ctx.Done():
+		return false
+	case <-c.stopCh:
+		return false
+	}
+}
+
+func (c *clickEventConsumer) Run(ctx context.Context) {
+	defer close(c.stoppedCh)
+
+	queueName := c.cfg.RabbitMQ.Queues.ClickEvents
+
+	for {
+		deliveries, err := c.channel.Consume(queueName, "", false, false, false, false, nil)
+		if err != nil {
+			c.logger.Error("failed to start click event consumer, retrying", "queue", queueName, "error", err)
+			if !c.waitBeforeReopen(ctx) {
+				return
+			}
+			if err := c.reopen(); err != nil {
+				c.logger.Error("failed to reopen click event channel", "error", err)
+			}
+			continue
+		}
+
+	consume:
+		for {
+			select {
+			case delivery, ok := <-deliveries:
+				if !ok {
+					c.logger.Warn("click event consumer channel closed, reopening")
+					break consume
+				}
+				c.handle(context.Background(), delivery)
+
+			case <-ctx.Done():
+				return
+
+			case <-c.stopCh:
+				return
+			}
+		}
+
+		if !c.waitBeforeReopen(ctx) {
+			return
+		}
+		if err := c.reopen(); err != nil {
+			c.logger.Error("failed to reopen click event channel", "error", err)
+		}
+	}
+}
+
+func (c *clickEventConsumer) Shutdown(ctx context.Context) error {
+	c.stopOnce.Do(func() { close(c.stopCh) })
+
+	select {
+	case <-c.stoppedCh:
+		return c.channel.Close()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *clickEventConsumer) handle(ctx context.Context, delivery amqp.Delivery) {
+	var msg domain.ClickEventMessage
+	if err := json.Unmarshal(delivery.Body, &msg); err != nil {
+		// A message this malformed will never unmarshal no matter how many
+		// times it's retried - straight to the DLQ.
+		c.logger.Error("failed to unmarshal click event, dead-lettering", "error", err)
+		clickEventsConsumedTotal.WithLabelValues("poison").Inc()
+		clickEventsDeadLetteredTotal.Inc()
+		delivery.Nack(false, false)
+		return
+	}
+
+	evt := c.enrich(ctx, msg)
+
+	if err := c.ingester.Enqueue(ctx, evt); err != nil {
+		c.logger.Warn("failed to enqueue click event for ingestion, retrying", "shortCode", msg.ShortCode, "error", err)
+		clickEventsConsumedTotal.WithLabelValues("retry").Inc()
+		c.retry(delivery)
+		return
+	}
+
+	clickEventsConsumedTotal.WithLabelValues("ok").Inc()
+	delivery.Ack(false)
+}
+
+// retry requeues delivery onto click_events.retry with a per-message TTL
+// computed from how many times it's already been through that queue
+// (delivery.Headers["x-death"]), so each attempt backs off further than the
+// last. Once ClickConsumerMaxRetries is exhausted, it's nacked onto the DLQ
+// instead.
+func (c *clickEventConsumer) retry(delivery amqp.Delivery) {
+	attempt := retryAttempt(delivery, c.cfg.RabbitMQ.Queues.ClickEventsRetry)
+
+	if attempt >= c.cfg.RabbitMQ.ClickConsumerMaxRetries {
+		c.logger.Warn("click event exceeded max retries, dead-lettering", "attempt", attempt)
+		clickEventsDeadLetteredTotal.Inc()
+		delivery.Nack(false, false)
+		return
+	}
+
+	delay := backoff(attempt, c.cfg.RabbitMQ.ClickConsumerRetryBaseDelay, c.cfg.RabbitMQ.ClickConsumerRetryMaxDelay)
+
+	err := c.channel.PublishWithContext(
+		context.Background(),
+		"",
+		c.cfg.RabbitMQ.Queues.ClickEventsRetry,
+		false,
+		false,
+		amqp.Publishing{
+			ContentType:  delivery.ContentType,
+			Body:         delivery.Body,
+			DeliveryMode: amqp.Persistent,
+			Expiration:   millisecondsString(delay),
+			Timestamp:    time.Now(),
+		},
+	)
+	if err != nil {
+		c.logger.Error("failed to republish click event to retry queue, dead-lettering", "error", err)
+		clickEventsDeadLetteredTotal.Inc()
+		delivery.Nack(false, false)
+		return
+	}
+
+	clickEventsRetriedTotal.Inc()
+	delivery.Ack(false)
+}
+
+// retryAttempt counts how many times a delivery has already been through
+// retryQueue, per the x-death header array RabbitMQ attaches to a
+// dead-lettered message.
+func retryAttempt(delivery amqp.Delivery, retryQueue string) int {
+	deaths, ok := delivery.Headers["x-death"].([]interface{})
+	if !ok {
+		return 0
+	}
+
+	for _, d := range deaths {
+		entry, ok := d.(amqp.Table)
+		if !ok {
+			continue
+		}
+		if queue, _ := entry["queue"].(string); queue != retryQueue {
+			continue
+		}
+		switch count := entry["count"].(type) {
+		case int64:
+			return int(count)
+		case int32:
+			return int(count)
+		}
+	}
+
+	return 0
+}
+
+// backoff returns base*2^attempt, capped at max.
+func backoff(attempt int, base, max time.Duration) time.Duration {
+	delay := base << attempt
+	if delay <= 0 || delay > max {
+		return max
+	}
+	return delay
+}
+
+func millisecondsString(d time.Duration) string {
+	return strconv.Itoa(int(d.Milliseconds()))
+}
+
+func (c *clickEventConsumer) enrich(ctx context.Context, msg domain.ClickEventMessage) *domain.ClickEvent {
+	return enrichClickEvent(ctx, msg, c.geoIPService, c.botDetector, c.logger)
+}
+
+// enrichClickEvent does the UA parse, GeoIP lookup, bot detection, IP hash,
+// and domain extraction TrackClick used to do synchronously, shared by both
+// the RabbitMQ and eventbus-backed click event consumers so neither
+// reimplements it.
+func enrichClickEvent(ctx context.Context, msg domain.ClickEventMessage, geoIPService GeoIPService, botDetector BotDetector, log logger.Logger) *domain.ClickEvent {
+	ua := useragent.Parse(msg.UserAgent)
+
+	geoCtx, geoSpan := tracing.Tracer().Start(ctx, "click_event_consumer.geoip_lookup")
+	geoStart := time.Now()
+	geoLocation, err := geoIPService.GetLocation(geoCtx, msg.ClientIP)
+	geoSpan.SetAttributes(attribute.Int64("geoip.lookup_ms", time.Since(geoStart).Milliseconds()))
+	geoSpan.End()
+	if err != nil {
+		log.WarnContext(ctx, "failed to get geolocation", "ip", msg.ClientIP, "error", err)
+	}
+
+	botResult := botDetector.Detect(ctx, msg.ClickMetadata, ua)
+
+	evt := &domain.ClickEvent{
+		ClickedAt:      msg.Timestamp,
+		ShortCode:      msg.ShortCode,
+		IPAddressHash:  hashIPAddress(msg.ClientIP),
+		UserAgent:      msg.UserAgent,
+		ReferrerURL:    msg.Referrer,
+		ReferrerDomain: extractDomain(msg.Referrer),
+		DeviceType:     getDeviceType(ua),
+		BrowserName:    ua.Name,
+		BrowserVersion: ua.Version,
+		OSName:         ua.OS,
+		OSVersion:      ua.OSVersion,
+		IsBot:          botResult.IsBot,
+		BotScore:       botResult.Confidence,
+		BotReasons:     botResult.Reasons,
+	}
+
+	if geoLocation != nil {
+		evt.CountryCode = geoLocation.CountryCode
+		evt.City = geoLocation.City
+		evt.Region = geoLocation.RegionName
+		evt.Lat = geoLocation.Lat
+		evt.Lon = geoLocation.Lon
+	}
+
+	return evt
+}
+
+func hashIPAddress(ip string) string {
+	hash := sha256.Sum256([]byte(ip))
+	return hex.EncodeToString(hash[:])
+}
+
+func getDeviceType(ua useragent.UserAgent) string {
+	if ua.Mobile {
+		return "mobile"
+	}
+	if ua.Tablet {
+		return "tablet"
+	}
+	if ua.Desktop {
+		return "desktop"
+	}
+	return "unknown"
+}
+
+func extractDomain(url string) string {
+	if url == "" {
+		return ""
+	}
+
+	// Remove protocol
+	url = strings.TrimPrefix(url, "http://")
+	url = strings.TrimPrefix(url, "https://")
+
+	// Get domain (before first /)
+	parts := strings.Split(url, "/")
+	if len(parts) > 0 {
+		return parts[0]
+	}
+
+	return url
+}
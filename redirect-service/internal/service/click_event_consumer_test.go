@@ -0,0 +1,67 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestBackoff_DoublesUntilCap(t *testing.T) {
+	base := 1 * time.Second
+	max := 30 * time.Second
+
+	cases := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, 1 * time.Second},
+		{1, 2 * time.Second},
+		{2, 4 * time.Second},
+		{5, 30 * time.Second}, // 32s uncapped, clamped to max
+	}
+
+	for _, tc := range cases {
+		if got := backoff(tc.attempt, base, max); got != tc.want {
+			t.Errorf("backoff(%d, %s, %s) = %s, want %s", tc.attempt, base, max, got, tc.want)
+		}
+	}
+}
+
+func TestRetryAttempt_CountsDeathsOnRetryQueue(t *testing.T) {
+	delivery := amqp.Delivery{
+		Headers: amqp.Table{
+			"x-death": []interface{}{
+				amqp.Table{"queue": "click_events.retry", "count": int64(3)},
+				amqp.Table{"queue": "click_events.dlq", "count": int64(1)},
+			},
+		},
+	}
+
+	if got := retryAttempt(delivery, "click_events.retry"); got != 3 {
+		t.Errorf("retryAttempt() = %d, want 3", got)
+	}
+}
+
+func TestRetryAttempt_NoHistoryIsZero(t *testing.T) {
+	delivery := amqp.Delivery{Headers: amqp.Table{}}
+
+	if got := retryAttempt(delivery, "click_events.retry"); got != 0 {
+		t.Errorf("retryAttempt() = %d, want 0", got)
+	}
+}
+
+func TestExtractDomain(t *testing.T) {
+	cases := map[string]string{
+		"":                             "",
+		"https://example.com/path":     "example.com",
+		"http://sub.example.com":       "sub.example.com",
+		"example.com/a/b":              "example.com",
+	}
+
+	for in, want := range cases {
+		if got := extractDomain(in); got != want {
+			t.Errorf("extractDomain(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,398 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hoggir/re-path/redirect-service/internal/config"
+	"github.com/hoggir/re-path/redirect-service/internal/domain"
+	"github.com/hoggir/re-path/redirect-service/internal/logger"
+	"github.com/hoggir/re-path/redirect-service/internal/repository"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Backpressure modes for ClickEventIngester.Enqueue, selected via
+// Config.ClickIngestion.BackpressureMode.
+const (
+	BackpressureBlock      = "block"
+	BackpressureDropOldest = "drop_oldest"
+	BackpressureDropNewest = "drop_newest"
+)
+
+var (
+	clickIngestQueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "repath_click_ingest_queue_depth",
+		Help: "Number of click events currently buffered in the ingester.",
+	})
+
+	clickIngestBatchSize = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "repath_click_ingest_batch_size",
+		Help:    "Number of click events per flushed batch.",
+		Buckets: []float64{1, 10, 50, 100, 250, 500, 1000, 2000},
+	})
+
+	clickIngestFlushDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "repath_click_ingest_flush_duration_seconds",
+		Help:    "Batch flush duration by sink.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"sink"})
+
+	clickIngestDroppedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "repath_click_ingest_dropped_total",
+		Help: "Click events dropped by backpressure mode, by reason.",
+	}, []string{"reason"})
+
+	clickIngestESBulkErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "repath_click_ingest_es_bulk_errors_total",
+		Help: "Elasticsearch bulk documents rejected, across all batches.",
+	})
+)
+
+// ClickEventIngester decouples click tracking from the redirect hot path: it
+// buffers ClickEvents behind a bounded channel and flushes them in batches
+// to MongoDB (InsertMany) and, optionally, Elasticsearch (_bulk), from a
+// pool of worker goroutines. A batch flushes once BatchMaxSize events have
+// accumulated or FlushInterval has elapsed, whichever comes first.
+type ClickEventIngester interface {
+	// Enqueue accepts evt per Config.ClickIngestion.BackpressureMode: it
+	// never blocks on I/O, only - in "block" mode - on buffer space.
+	Enqueue(ctx context.Context, evt *domain.ClickEvent) error
+	// Run replays any leftover WAL segments from a previous crash, then
+	// drives the flush loop until Shutdown is called.
+	Run(ctx context.Context)
+	// Shutdown stops accepting new flush triggers, drains whatever is
+	// currently buffered through one last flush, and waits for in-flight
+	// worker batches to finish, or until ctx is done.
+	Shutdown(ctx context.Context) error
+}
+
+type clickEventIngester struct {
+	repo repository.ClickEventRepository
+	es   *elasticsearchBulkClient
+	wal  *clickEventWAL
+	cfg  *config.Config
+	log  logger.Logger
+
+	queue            chan *domain.ClickEvent
+	flushCh          chan clickEventBatch
+	backpressureMode string
+
+	wg        sync.WaitGroup
+	stopOnce  sync.Once
+	stopCh    chan struct{}
+	stoppedCh chan struct{}
+}
+
+// clickEventBatch is one unit of work handed from the dispatch loop to a
+// worker: the events themselves, plus the WAL segment backing them (empty
+// when the WAL is disabled).
+type clickEventBatch struct {
+	events  []*domain.ClickEvent
+	walPath string
+}
+
+func NewClickEventIngester(repo repository.ClickEventRepository, cfg *config.Config, log logger.Logger) (ClickEventIngester, error) {
+	var wal *clickEventWAL
+	if cfg.ClickIngestion.WALEnabled {
+		w, err := newClickEventWAL(cfg.ClickIngestion.WALDir)
+		if err != nil {
+			return nil, fmt.Errorf("click event ingester: %w", err)
+		}
+		wal = w
+	}
+
+	var es *elasticsearchBulkClient
+	if cfg.ClickIngestion.ESEnabled {
+		es = newElasticsearchBulkClient(cfg.ClickIngestion.ESBulkURL, cfg.ClickIngestion.ESTimeout)
+	}
+
+	mode := cfg.ClickIngestion.BackpressureMode
+	if mode != BackpressureDropOldest && mode != BackpressureDropNewest {
+		mode = BackpressureBlock
+	}
+
+	return &clickEventIngester{
+		repo:             repo,
+		es:               es,
+		wal:              wal,
+		cfg:              cfg,
+		log:              log,
+		queue:            make(chan *domain.ClickEvent, cfg.ClickIngestion.BufferSize),
+		flushCh:          make(chan clickEventBatch, cfg.ClickIngestion.Workers*2),
+		backpressureMode: mode,
+		stopCh:           make(chan struct{}),
+		stoppedCh:        make(chan struct{}),
+	}, nil
+}
+
+func (ing *clickEventIngester) Enqueue(ctx context.Context, evt *domain.ClickEvent) error {
+	select {
+	case <-ing.stopCh:
+		return domain.ErrServiceUnavailable.WithMessage("click event ingester is shutting down")
+	default:
+	}
+
+	switch ing.backpressureMode {
+	case BackpressureDropNewest:
+		select {
+		case ing.queue <- evt:
+		default:
+			clickIngestDroppedTotal.WithLabelValues(BackpressureDropNewest).Inc()
+		}
+		return nil
+
+	case BackpressureDropOldest:
+		for {
+			select {
+			case ing.queue <- evt:
+				return nil
+			default:
+			}
+			select {
+			case <-ing.queue:
+				clickIngestDroppedTotal.WithLabelValues(BackpressureDropOldest).Inc()
+			default:
+			}
+		}
+
+	default: // BackpressureBlock
+		select {
+		case ing.queue <- evt:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ing.stopCh:
+			return domain.ErrServiceUnavailable.WithMessage("click event ingester is shutting down")
+		}
+	}
+}
+
+func (ing *clickEventIngester) Run(ctx context.Context) {
+	for i := 0; i < ing.cfg.ClickIngestion.Workers; i++ {
+		ing.wg.Add(1)
+		go ing.worker()
+	}
+
+	// Replay in the background: it re-enqueues through the same bounded
+	// Enqueue as live traffic, and in BackpressureBlock mode that blocks
+	// until the dispatch loop below is draining ing.queue. Running it
+	// synchronously here, before that loop starts, would wedge forever on
+	// any WAL backlog bigger than BufferSize.
+	if ing.wal != nil {
+		go ing.replayWAL(ctx)
+	}
+
+	ticker := time.NewTicker(ing.cfg.ClickIngestion.FlushInterval)
+	defer ticker.Stop()
+
+	var pending []*domain.ClickEvent
+
+	for {
+		select {
+		case evt := <-ing.queue:
+			pending = append(pending, evt)
+			clickIngestQueueDepth.Set(float64(len(ing.queue)))
+			if len(pending) >= ing.cfg.ClickIngestion.BatchMaxSize {
+				ing.dispatch(pending)
+				pending = nil
+			}
+
+		case <-ticker.C:
+			if len(pending) > 0 {
+				ing.dispatch(pending)
+				pending = nil
+			}
+
+		case <-ctx.Done():
+			ing.drainAndStop(&pending)
+			return
+
+		case <-ing.stopCh:
+			ing.drainAndStop(&pending)
+			return
+		}
+	}
+}
+
+// drainAndStop flushes whatever is left in pending plus whatever is still
+// buffered in the queue, then waits for every worker to finish before
+// signaling Shutdown's caller via stoppedCh.
+func (ing *clickEventIngester) drainAndStop(pending *[]*domain.ClickEvent) {
+drain:
+	for {
+		select {
+		case evt := <-ing.queue:
+			*pending = append(*pending, evt)
+			if len(*pending) >= ing.cfg.ClickIngestion.BatchMaxSize {
+				ing.dispatch(*pending)
+				*pending = nil
+			}
+		default:
+			break drain
+		}
+	}
+
+	if len(*pending) > 0 {
+		ing.dispatch(*pending)
+		*pending = nil
+	}
+
+	close(ing.flushCh)
+	ing.wg.Wait()
+	close(ing.stoppedCh)
+}
+
+func (ing *clickEventIngester) Shutdown(ctx context.Context) error {
+	ing.stopOnce.Do(func() { close(ing.stopCh) })
+
+	select {
+	case <-ing.stoppedCh:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// dispatch hands batch to a worker, WAL-backed if enabled: the segment is
+// written and fsync'd before the batch becomes visible to workers, so a
+// crash right after this call still leaves the batch recoverable at
+// startup.
+func (ing *clickEventIngester) dispatch(batch []*domain.ClickEvent) {
+	job := clickEventBatch{events: batch}
+
+	if ing.wal != nil {
+		path, err := ing.wal.WriteSegment(batch)
+		if err != nil {
+			ing.log.Error("failed to write click event wal segment", "batchSize", len(batch), "error", err)
+		} else {
+			job.walPath = path
+		}
+	}
+
+	ing.flushCh <- job
+}
+
+func (ing *clickEventIngester) worker() {
+	defer ing.wg.Done()
+	for job := range ing.flushCh {
+		ing.flushBatch(job)
+	}
+}
+
+func (ing *clickEventIngester) flushBatch(job clickEventBatch) {
+	clickIngestBatchSize.Observe(float64(len(job.events)))
+
+	start := time.Now()
+	err := ing.repo.CreateMany(context.Background(), job.events)
+	clickIngestFlushDuration.WithLabelValues("mongo").Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		ing.log.Error("failed to flush click event batch to mongodb", "batchSize", len(job.events), "error", err)
+		// Leave the WAL segment in place so it's replayed on the next
+		// startup instead of being silently lost.
+	} else if job.walPath != "" {
+		if err := ing.wal.RemoveSegment(job.walPath); err != nil {
+			ing.log.Warn("failed to remove click event wal segment", "path", job.walPath, "error", err)
+		}
+	}
+
+	if ing.es == nil {
+		return
+	}
+
+	esStart := time.Now()
+	payloads := make([]domain.PayloadElasticClick, len(job.events))
+	for i, evt := range job.events {
+		payloads[i] = toElasticPayload(evt, ing.cfg.ClickIngestion.ESIndexPrefix)
+	}
+
+	failures, esErr := ing.es.Bulk(context.Background(), payloads)
+	clickIngestFlushDuration.WithLabelValues("elasticsearch").Observe(time.Since(esStart).Seconds())
+
+	if esErr != nil {
+		ing.log.Warn("elasticsearch bulk request failed", "batchSize", len(payloads), "error", esErr)
+		return
+	}
+	if len(failures) == 0 {
+		return
+	}
+
+	clickIngestESBulkErrors.Add(float64(len(failures)))
+	ing.log.Warn("elasticsearch bulk indexing had partial failures", "failedCount", len(failures), "batchSize", len(payloads))
+
+	// Retry only the documents the bulk response actually rejected, so the
+	// ones it accepted aren't re-indexed.
+	for _, failure := range failures {
+		if failure.Index < 0 || failure.Index >= len(job.events) {
+			continue
+		}
+		if err := ing.Enqueue(context.Background(), job.events[failure.Index]); err != nil {
+			ing.log.Error("failed to re-enqueue elasticsearch bulk failure", "reason", failure.Reason, "error", err)
+		}
+	}
+}
+
+// replayWAL re-enqueues events from any segment left over by a crash between
+// a previous dispatch and its flush, then removes the segments once their
+// events are back in the queue.
+func (ing *clickEventIngester) replayWAL(ctx context.Context) {
+	events, paths, err := ing.wal.ReplaySegments()
+	if err != nil {
+		ing.log.Error("failed to replay click event wal", "error", err)
+		return
+	}
+	if len(events) == 0 {
+		return
+	}
+
+	ing.log.Warn("replaying click events from wal after restart", "eventCount", len(events), "segmentCount", len(paths))
+	for _, evt := range events {
+		if err := ing.Enqueue(ctx, evt); err != nil {
+			ing.log.Error("failed to re-enqueue replayed click event", "shortCode", evt.ShortCode, "error", err)
+		}
+	}
+
+	for _, path := range paths {
+		if err := ing.wal.RemoveSegment(path); err != nil {
+			ing.log.Warn("failed to remove replayed click event wal segment", "path", path, "error", err)
+		}
+	}
+}
+
+func toElasticPayload(evt *domain.ClickEvent, indexPrefix string) domain.PayloadElasticClick {
+	return domain.PayloadElasticClick{
+		IndexType: fmt.Sprintf("%s-%s", indexPrefix, evt.ClickedAt.Format("2006.01.02")),
+		Data: domain.ClickData{
+			ShortCode: evt.ShortCode,
+			Metadata: domain.ClickMetaData{
+				ClickedAt: evt.ClickedAt,
+				IsBot:     evt.IsBot,
+				Client: domain.ClientInfo{
+					IPHash: evt.IPAddressHash,
+					Geo: domain.GeoInfo{
+						CountryISOCode: evt.CountryCode,
+						RegionName:     evt.Region,
+						City:           evt.City,
+						Location: domain.GeoLocationElastic{
+							Lat: evt.Lat,
+							Lon: evt.Lon,
+						},
+					},
+				},
+				HTTP: domain.HTTPInfo{
+					Referrer:       evt.ReferrerURL,
+					ReferrerDomain: evt.ReferrerDomain,
+				},
+				UserAgent: domain.UserAgentInfo{
+					Original: evt.UserAgent,
+					Device:   domain.DeviceInfo{Name: evt.DeviceType},
+					Browser:  domain.BrowserInfo{Name: evt.BrowserName, Version: evt.BrowserVersion},
+					OS:       domain.OSInfo{Name: evt.OSName, Version: evt.OSVersion},
+				},
+			},
+		},
+	}
+}
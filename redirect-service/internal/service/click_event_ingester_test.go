@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hoggir/re-path/redirect-service/internal/config"
+	"github.com/hoggir/re-path/redirect-service/internal/domain"
+)
+
+// fakeClickEventRepo is a minimal ClickEventRepository double that records
+// every batch handed to CreateMany, optionally failing on demand.
+type fakeClickEventRepo struct {
+	mu      sync.Mutex
+	batches [][]*domain.ClickEvent
+	failErr error
+}
+
+func (r *fakeClickEventRepo) Create(ctx context.Context, evt *domain.ClickEvent) error {
+	return r.CreateMany(ctx, []*domain.ClickEvent{evt})
+}
+
+func (r *fakeClickEventRepo) CreateMany(ctx context.Context, evts []*domain.ClickEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failErr != nil {
+		return r.failErr
+	}
+	r.batches = append(r.batches, evts)
+	return nil
+}
+
+func (r *fakeClickEventRepo) eventCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, b := range r.batches {
+		n += len(b)
+	}
+	return n
+}
+
+func (r *fakeClickEventRepo) batchCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.batches)
+}
+
+func testIngestConfig(t *testing.T) *config.Config {
+	t.Helper()
+	return &config.Config{
+		ClickIngestion: config.ClickIngestionConfig{
+			BufferSize:       16,
+			BatchMaxSize:     4,
+			FlushInterval:    20 * time.Millisecond,
+			Workers:          2,
+			BackpressureMode: BackpressureBlock,
+			WALEnabled:       false,
+			ESEnabled:        false,
+		},
+	}
+}
+
+func TestClickEventIngester_FlushesOnBatchMaxSize(t *testing.T) {
+	repo := &fakeClickEventRepo{}
+	cfg := testIngestConfig(t)
+	cfg.ClickIngestion.FlushInterval = time.Hour // only the size threshold should trigger a flush
+
+	ing, err := NewClickEventIngester(repo, cfg, testLogger())
+	if err != nil {
+		t.Fatalf("NewClickEventIngester: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go ing.Run(ctx)
+
+	for i := 0; i < 4; i++ {
+		if err := ing.Enqueue(context.Background(), &domain.ClickEvent{ShortCode: "abc"}); err != nil {
+			t.Fatalf("Enqueue: %v", err)
+		}
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for repo.eventCount() < 4 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := repo.eventCount(); got != 4 {
+		t.Fatalf("expected 4 events flushed by batch size, got %d", got)
+	}
+}
+
+func TestClickEventIngester_FlushesOnInterval(t *testing.T) {
+	repo := &fakeClickEventRepo{}
+	cfg := testIngestConfig(t)
+	cfg.ClickIngestion.BatchMaxSize = 100 // only the interval should trigger a flush
+
+	ing, err := NewClickEventIngester(repo, cfg, testLogger())
+	if err != nil {
+		t.Fatalf("NewClickEventIngester: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go ing.Run(ctx)
+
+	if err := ing.Enqueue(context.Background(), &domain.ClickEvent{ShortCode: "abc"}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for repo.eventCount() < 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := repo.eventCount(); got != 1 {
+		t.Fatalf("expected 1 event flushed by interval, got %d", got)
+	}
+}
+
+func TestClickEventIngester_ShutdownDrainsPendingEvents(t *testing.T) {
+	repo := &fakeClickEventRepo{}
+	cfg := testIngestConfig(t)
+	cfg.ClickIngestion.FlushInterval = time.Hour
+	cfg.ClickIngestion.BatchMaxSize = 100
+
+	ing, err := NewClickEventIngester(repo, cfg, testLogger())
+	if err != nil {
+		t.Fatalf("NewClickEventIngester: %v", err)
+	}
+
+	go ing.Run(context.Background())
+
+	for i := 0; i < 3; i++ {
+		if err := ing.Enqueue(context.Background(), &domain.ClickEvent{ShortCode: "abc"}); err != nil {
+			t.Fatalf("Enqueue: %v", err)
+		}
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := ing.Shutdown(shutdownCtx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	if got := repo.eventCount(); got != 3 {
+		t.Fatalf("expected shutdown to drain 3 pending events, got %d", got)
+	}
+
+	if err := ing.Enqueue(context.Background(), &domain.ClickEvent{ShortCode: "abc"}); err == nil {
+		t.Fatal("expected Enqueue to reject events after Shutdown")
+	}
+}
+
+func TestClickEventIngester_DropOldestBackpressure(t *testing.T) {
+	repo := &fakeClickEventRepo{failErr: nil}
+	cfg := testIngestConfig(t)
+	cfg.ClickIngestion.BufferSize = 2
+	cfg.ClickIngestion.BackpressureMode = BackpressureDropOldest
+	cfg.ClickIngestion.FlushInterval = time.Hour
+	cfg.ClickIngestion.BatchMaxSize = 100
+
+	ing, err := NewClickEventIngester(repo, cfg, testLogger())
+	if err != nil {
+		t.Fatalf("NewClickEventIngester: %v", err)
+	}
+
+	impl := ing.(*clickEventIngester)
+
+	// Fill the queue directly so Enqueue has to evict rather than flush.
+	impl.queue <- &domain.ClickEvent{ShortCode: "1"}
+	impl.queue <- &domain.ClickEvent{ShortCode: "2"}
+
+	if err := ing.Enqueue(context.Background(), &domain.ClickEvent{ShortCode: "3"}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	if len(impl.queue) != 2 {
+		t.Fatalf("expected queue to stay bounded at 2, got %d", len(impl.queue))
+	}
+	first := <-impl.queue
+	if first.ShortCode != "2" {
+		t.Errorf("expected oldest event to be evicted, oldest remaining is %q, want %q", first.ShortCode, "2")
+	}
+}
+
+func TestClickEventIngester_ElasticsearchPartialFailureIsRetried(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		if n == 1 {
+			w.Write([]byte(`{"errors":true,"items":[{"index":{"status":200}},{"index":{"status":429,"error":{"type":"es_rejected_execution_exception","reason":"queue full"}}}]}`))
+			return
+		}
+		w.Write([]byte(`{"errors":false,"items":[{"index":{"status":200}}]}`))
+	}))
+	defer server.Close()
+
+	repo := &fakeClickEventRepo{}
+	cfg := testIngestConfig(t)
+	cfg.ClickIngestion.FlushInterval = 20 * time.Millisecond
+	cfg.ClickIngestion.BatchMaxSize = 2
+	cfg.ClickIngestion.ESEnabled = true
+	cfg.ClickIngestion.ESBulkURL = server.URL
+	cfg.ClickIngestion.ESIndexPrefix = "click_events"
+	cfg.ClickIngestion.ESTimeout = time.Second
+
+	ing, err := NewClickEventIngester(repo, cfg, testLogger())
+	if err != nil {
+		t.Fatalf("NewClickEventIngester: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go ing.Run(ctx)
+
+	now := time.Unix(0, 0)
+	if err := ing.Enqueue(context.Background(), &domain.ClickEvent{ShortCode: "a", ClickedAt: now}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if err := ing.Enqueue(context.Background(), &domain.ClickEvent{ShortCode: "b", ClickedAt: now}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&requests) < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := atomic.LoadInt32(&requests); got < 2 {
+		t.Fatalf("expected the rejected document to be retried in a second bulk request, got %d requests", got)
+	}
+}
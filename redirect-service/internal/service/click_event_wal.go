@@ -0,0 +1,126 @@
+package service
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/hoggir/re-path/redirect-service/internal/domain"
+)
+
+// clickEventWAL writes one segment file per batch under dir, so a crash
+// between "batch accepted" and "batch flushed" doesn't lose events: on
+// restart, ReplaySegments reads back whatever segments are still on disk
+// (earlier batches that never got removed) for re-ingestion. A segment is
+// removed once its batch has been durably written to MongoDB, regardless of
+// any Elasticsearch outcome - Mongo is the system of record here.
+//
+// Because a replayed segment is re-enqueued through the normal path (which
+// writes a *new* segment for it), a crash during replay itself can produce
+// duplicate documents; that's an accepted at-least-once tradeoff over the
+// complexity of a fully transactional WAL.
+type clickEventWAL struct {
+	dir string
+	seq uint64
+}
+
+func newClickEventWAL(dir string) (*clickEventWAL, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("click event wal: create dir: %w", err)
+	}
+	return &clickEventWAL{dir: dir}, nil
+}
+
+// WriteSegment durably writes batch as one NDJSON file (fsync'd before
+// return) and reports its path, so the caller can remove it once the batch
+// is flushed.
+func (w *clickEventWAL) WriteSegment(batch []*domain.ClickEvent) (string, error) {
+	path := filepath.Join(w.dir, fmt.Sprintf("%020d.wal", atomic.AddUint64(&w.seq, 1)))
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return "", fmt.Errorf("click event wal: open segment: %w", err)
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	for _, evt := range batch {
+		if err := enc.Encode(evt); err != nil {
+			return "", fmt.Errorf("click event wal: encode event: %w", err)
+		}
+	}
+
+	if err := f.Sync(); err != nil {
+		return "", fmt.Errorf("click event wal: fsync segment: %w", err)
+	}
+
+	return path, nil
+}
+
+// RemoveSegment deletes a flushed segment; a missing file is not an error
+// since Shutdown/replay may race with a concurrent cleanup.
+func (w *clickEventWAL) RemoveSegment(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("click event wal: remove segment %s: %w", path, err)
+	}
+	return nil
+}
+
+// ReplaySegments reads back every leftover segment in dir, oldest first, and
+// returns the events they held plus their paths so the caller can remove
+// them once the events have been re-enqueued.
+func (w *clickEventWAL) ReplaySegments() ([]*domain.ClickEvent, []string, error) {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("click event wal: read dir: %w", err)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".wal") {
+			continue
+		}
+		paths = append(paths, filepath.Join(w.dir, entry.Name()))
+	}
+	sort.Strings(paths)
+
+	var events []*domain.ClickEvent
+	for _, path := range paths {
+		segmentEvents, err := readWALSegment(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("click event wal: replay %s: %w", path, err)
+		}
+		events = append(events, segmentEvents...)
+	}
+
+	return events, paths, nil
+}
+
+func readWALSegment(path string) ([]*domain.ClickEvent, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var events []*domain.ClickEvent
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var evt domain.ClickEvent
+		if err := json.Unmarshal(line, &evt); err != nil {
+			return nil, err
+		}
+		events = append(events, &evt)
+	}
+	return events, scanner.Err()
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hoggir/re-path/redirect-service/internal/config"
+	"github.com/hoggir/re-path/redirect-service/internal/contract"
+	"github.com/hoggir/re-path/redirect-service/internal/domain"
+	"github.com/hoggir/re-path/redirect-service/internal/eventbus"
+	"github.com/hoggir/re-path/redirect-service/internal/logger"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// DashboardClient is a deadline-aware wrapper around the dashboard_request
+// RPC, built on domain.ContractValidator rather than the generic TypedCall
+// path so the dashboard call gets its own send/receive deadline knobs. The
+// deadlines follow the net.Conn convention - SetRequestDeadline is checked
+// once before the request is handed to the RPC transport, SetResponseDeadline
+// bounds waiting for analytic-service's reply after that - and either one firing
+// is reported as a "limited" DashboardResponse (see domain.DashboardResponse
+// .IsLimited) instead of an error, so a slow analytic-service degrades the
+// dashboard instead of failing the request outright.
+//
+// A DashboardClient is built to be shared (it's wired once through DI), so
+// the deadlines it holds are shared state too: set them immediately before
+// each GetDashboard call, the same way a caller would call SetDeadline right
+// before Read/Write on a pooled net.Conn.
+type DashboardClient interface {
+	GetDashboard(ctx context.Context, req domain.DashboardRequest) (*domain.DashboardResponse, error)
+	// SetRequestDeadline is checked once, when GetDashboard starts: if it has
+	// already passed, the request is never handed to the RPC transport. Once
+	// the request is sent, only the response deadline and ctx apply. A zero
+	// Time clears the deadline.
+	SetRequestDeadline(t time.Time)
+	// SetResponseDeadline bounds how long GetDashboard waits for
+	// analytic-service's reply once the request has been sent. A zero Time
+	// clears the deadline.
+	SetResponseDeadline(t time.Time)
+}
+
+// deadline pairs a *time.Timer with a channel closed when it fires, mirroring
+// how the standard library implements net.Conn deadlines internally: callers
+// select on Chan() instead of polling time.Now(). The zero value has no
+// deadline set - Chan() returns nil, which blocks forever in a select.
+type deadline struct {
+	timer *time.Timer
+	ch    chan struct{}
+}
+
+// set installs t as the new deadline, stopping any previously scheduled
+// timer first. If Stop reports the old timer already fired (and is racing to
+// close d.ch, or already has), d.ch is replaced so callers that select on the
+// channel returned by a later Chan() call don't immediately see a stale
+// firing. A zero t clears the deadline; a t already in the past closes the
+// channel immediately instead of scheduling a timer for a negative duration.
+func (d *deadline) set(t time.Time) {
+	if d.timer != nil && !d.timer.Stop() {
+		d.ch = nil
+	}
+	d.timer = nil
+	// A channel already closed by an earlier past-due set is never reused.
+	select {
+	case <-d.ch:
+		d.ch = nil
+	default:
+	}
+	if t.IsZero() {
+		d.ch = nil
+		return
+	}
+
+	if d.ch == nil {
+		d.ch = make(chan struct{})
+	}
+	ch := d.ch
+
+	if until := time.Until(t); until > 0 {
+		d.timer = time.AfterFunc(until, func() { close(ch) })
+	} else {
+		close(ch)
+	}
+}
+
+// Chan returns the channel that closes when the deadline fires, or nil if no
+// deadline is set.
+func (d *deadline) Chan() <-chan struct{} {
+	return d.ch
+}
+
+type dashboardClient struct {
+	rpc     RPCService
+	queue   string
+	timeout time.Duration
+	logger  logger.Logger
+
+	mu         sync.Mutex
+	requestDL  deadline
+	responseDL deadline
+}
+
+// NewDashboardClient takes RPCService rather than RabbitMQRPCService
+// directly, so it works unchanged whichever backend NewRPCService picked for
+// config.EventBusConfig.Driver.
+func NewDashboardClient(rpc RPCService, cfg *config.Config, log logger.Logger) DashboardClient {
+	route := cfg.RabbitMQ.Queues.DashboardRequest
+	timeout := cfg.RabbitMQ.RPCTimeout
+	if eventbus.Driver(cfg.EventBus.Driver) == eventbus.DriverKafka {
+		route = cfg.Kafka.DashboardRequestTopic
+		timeout = cfg.Kafka.RPCTimeout
+	}
+
+	return &dashboardClient{
+		rpc:     rpc,
+		queue:   route,
+		timeout: timeout,
+		logger:  log,
+	}
+}
+
+func (c *dashboardClient) SetRequestDeadline(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requestDL.set(t)
+}
+
+func (c *dashboardClient) SetResponseDeadline(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.responseDL.set(t)
+}
+
+func (c *dashboardClient) GetDashboard(ctx context.Context, req domain.DashboardRequest) (*domain.DashboardResponse, error) {
+	c.mu.Lock()
+	requestCh := c.requestDL.Chan()
+	responseCh := c.responseDL.Chan()
+	c.mu.Unlock()
+
+	select {
+	case <-requestCh:
+		return limitedDashboardResponse(req.UserID, "dashboard request deadline elapsed before it could be sent"), nil
+	default:
+	}
+
+	body, err := domain.Validator.MarshalDashboardRequest(&req)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dashboard request: %w", err)
+	}
+
+	type callResult struct {
+		body []byte
+		err  error
+	}
+	done := make(chan callResult, 1)
+	go func() {
+		opts := DefaultCallOptions
+		opts.Headers = amqp.Table{"contract_version": contract.DashboardRequest.Version}
+		respBody, err := c.rpc.CallWithOptions(ctx, c.queue, json.RawMessage(body), c.timeout, opts)
+		done <- callResult{respBody, err}
+	}()
+
+	select {
+	case res := <-done:
+		if res.err != nil {
+			return nil, fmt.Errorf("dashboard RPC call failed: %w", res.err)
+		}
+		resp, err := domain.Validator.ValidateDashboardResponseJSON(res.body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dashboard response: %w", err)
+		}
+		return resp, nil
+
+	case <-responseCh:
+		c.logger.WarnContext(ctx, "dashboard response deadline fired before analytic-service replied", "userId", req.UserID, "queue", c.queue)
+		return limitedDashboardResponse(req.UserID, "analytic-service did not respond before the response deadline"), nil
+
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func limitedDashboardResponse(userID int, message string) *domain.DashboardResponse {
+	return &domain.DashboardResponse{
+		UserID:  userID,
+		Status:  "limited",
+		Message: &message,
+	}
+}
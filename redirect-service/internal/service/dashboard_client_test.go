@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hoggir/re-path/redirect-service/internal/domain"
+)
+
+func TestDeadline_ZeroValueNeverFires(t *testing.T) {
+	var d deadline
+	select {
+	case <-d.Chan():
+		t.Fatal("zero-value deadline must never fire")
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestDeadline_SetInPastClosesImmediately(t *testing.T) {
+	var d deadline
+	d.set(time.Now().Add(-time.Second))
+
+	select {
+	case <-d.Chan():
+	default:
+		t.Fatal("a deadline already in the past should close its channel immediately")
+	}
+}
+
+func TestDeadline_SetInFutureFiresOnce(t *testing.T) {
+	var d deadline
+	d.set(time.Now().Add(10 * time.Millisecond))
+
+	select {
+	case <-d.Chan():
+		t.Fatal("deadline fired before its time")
+	default:
+	}
+
+	select {
+	case <-d.Chan():
+	case <-time.After(time.Second):
+		t.Fatal("deadline never fired")
+	}
+}
+
+func TestDeadline_SetZeroClearsDeadline(t *testing.T) {
+	var d deadline
+	d.set(time.Now().Add(10 * time.Millisecond))
+	d.set(time.Time{})
+
+	select {
+	case <-d.Chan():
+		t.Fatal("Chan() should be nil (never fires) once the deadline is cleared")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestDeadline_ResetAfterFiringGetsFreshChannel(t *testing.T) {
+	var d deadline
+	d.set(time.Now().Add(-time.Second))
+	<-d.Chan() // drain the already-fired channel
+
+	d.set(time.Now().Add(time.Hour))
+	select {
+	case <-d.Chan():
+		t.Fatal("resetting after a firing must hand back a channel that isn't already closed")
+	default:
+	}
+}
+
+// fakeRPC is a minimal RabbitMQRPCService double for dashboardClient tests.
+type fakeRPC struct {
+	call func(ctx context.Context, queueName string, payload interface{}, timeout time.Duration, opts CallOptions) ([]byte, error)
+}
+
+func (f *fakeRPC) Call(ctx context.Context, queueName string, payload interface{}, timeout time.Duration) ([]byte, error) {
+	return f.CallWithOptions(ctx, queueName, payload, timeout, DefaultCallOptions)
+}
+
+func (f *fakeRPC) CallWithOptions(ctx context.Context, queueName string, payload interface{}, timeout time.Duration, opts CallOptions) ([]byte, error) {
+	return f.call(ctx, queueName, payload, timeout, opts)
+}
+
+func TestDashboardClient_GetDashboard_Success(t *testing.T) {
+	rpc := &fakeRPC{
+		call: func(ctx context.Context, queueName string, payload interface{}, timeout time.Duration, opts CallOptions) ([]byte, error) {
+			return json.Marshal(domain.DashboardResponse{UserID: 7, Status: "success"})
+		},
+	}
+	client := &dashboardClient{rpc: rpc, queue: "dashboard_request", timeout: time.Second, logger: testLogger()}
+
+	resp, err := client.GetDashboard(context.Background(), domain.DashboardRequest{UserID: 7})
+	if err != nil {
+		t.Fatalf("GetDashboard() error = %v", err)
+	}
+	if !resp.IsSuccess() || resp.UserID != 7 {
+		t.Errorf("GetDashboard() = %+v, want a success response for user 7", resp)
+	}
+}
+
+func TestDashboardClient_GetDashboard_ResponseDeadlineFiresAsLimited(t *testing.T) {
+	block := make(chan struct{})
+	t.Cleanup(func() { close(block) })
+
+	rpc := &fakeRPC{
+		call: func(ctx context.Context, queueName string, payload interface{}, timeout time.Duration, opts CallOptions) ([]byte, error) {
+			<-block // never replies within the test
+			return nil, context.Canceled
+		},
+	}
+	client := &dashboardClient{rpc: rpc, queue: "dashboard_request", timeout: time.Minute, logger: testLogger()}
+	client.SetResponseDeadline(time.Now().Add(10 * time.Millisecond))
+
+	resp, err := client.GetDashboard(context.Background(), domain.DashboardRequest{UserID: 9})
+	if err != nil {
+		t.Fatalf("GetDashboard() error = %v, want a limited response instead", err)
+	}
+	if !resp.IsLimited() {
+		t.Errorf("GetDashboard().Status = %q, want %q", resp.Status, "limited")
+	}
+}
+
+func TestDashboardClient_GetDashboard_RequestDeadlineAlreadyElapsed(t *testing.T) {
+	rpc := &fakeRPC{
+		call: func(ctx context.Context, queueName string, payload interface{}, timeout time.Duration, opts CallOptions) ([]byte, error) {
+			t.Fatal("the RPC transport should not be invoked once the request deadline has already elapsed")
+			return nil, nil
+		},
+	}
+	client := &dashboardClient{rpc: rpc, queue: "dashboard_request", timeout: time.Minute, logger: testLogger()}
+	client.SetRequestDeadline(time.Now().Add(-time.Second))
+
+	resp, err := client.GetDashboard(context.Background(), domain.DashboardRequest{UserID: 3})
+	if err != nil {
+		t.Fatalf("GetDashboard() error = %v, want a limited response instead", err)
+	}
+	if !resp.IsLimited() {
+		t.Errorf("GetDashboard().Status = %q, want %q", resp.Status, "limited")
+	}
+}
@@ -2,33 +2,74 @@ package service
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/hoggir/re-path/redirect-service/internal/config"
 	"github.com/hoggir/re-path/redirect-service/internal/domain"
 	"github.com/hoggir/re-path/redirect-service/internal/logger"
+	"github.com/hoggir/re-path/redirect-service/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/sync/singleflight"
 )
 
+// dashboardMetricsService names this service to metrics.ObserveCacheHit/
+// Miss/Invalidation, which partition by service across the whole process.
+const dashboardMetricsService = "dashboard"
+
+// dashboardCacheTotal partitions every GetDashboard call by how it was
+// served: "cache_fresh_hit" returned the cached value outright,
+// "cache_stale_hit" returned it past FreshUntil while a background refresh
+// ran, "cache_miss" blocked on a synchronous RPC refresh, and
+// "singleflight_shared" (counted alongside whichever of those a call also
+// triggered a refresh) means that refresh joined one already in flight for
+// the same userId instead of starting its own.
+var dashboardCacheTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "repath_dashboard_cache_total",
+	Help: "DashboardService.GetDashboard calls, partitioned by how the result was served.",
+}, []string{"result"})
+
 type DashboardService interface {
 	GetDashboard(ctx context.Context, userId int) (*domain.DashboardResponse, error)
 }
 
+// dashboardCacheEnvelope is what's stored at CacheKeyGenerator.Dashboard's
+// key: the response plus the fresh/stale timestamps that drive
+// dashboardService's own stale-while-revalidate layer. Redis's TTL on the
+// key (CacheStaleTTL) is what ultimately evicts it once StaleUntil passes,
+// so StaleUntil itself is kept only for observability rather than being
+// checked in code.
+type dashboardCacheEnvelope struct {
+	Response   domain.DashboardResponse `json:"response"`
+	FreshUntil time.Time                `json:"fresh_until"`
+	StaleUntil time.Time                `json:"stale_until"`
+}
+
+// dashboardService layers its own stale-while-revalidate cache on top of
+// DashboardClient's RPC call: fresh hits skip the RPC entirely, stale hits
+// return immediately while refreshing in the background, and misses (or an
+// explicit invalidation flag) refresh synchronously. sf collapses concurrent
+// refreshes for the same userId into a single RPC, whether they're
+// foreground misses or background stale refreshes.
 type dashboardService struct {
-	rpcService   RabbitMQRPCService
-	cacheService CacheService
-	cacheKeys    *CacheKeyGenerator
-	config       *config.Config
-	logger       logger.Logger
+	dashboardClient DashboardClient
+	cacheService    CacheService
+	cacheKeys       *CacheKeyGenerator
+	config          *config.Config
+	logger          logger.Logger
+
+	sf singleflight.Group
 }
 
-func NewDashboardService(rpcService RabbitMQRPCService, cacheService CacheService, cacheKeys *CacheKeyGenerator, cfg *config.Config, log logger.Logger) DashboardService {
+func NewDashboardService(dashboardClient DashboardClient, cacheService CacheService, cacheKeys *CacheKeyGenerator, cfg *config.Config, log logger.Logger) DashboardService {
 	return &dashboardService{
-		rpcService:   rpcService,
-		cacheService: cacheService,
-		cacheKeys:    cacheKeys,
-		config:       cfg,
-		logger:       log,
+		dashboardClient: dashboardClient,
+		cacheService:    cacheService,
+		cacheKeys:       cacheKeys,
+		config:          cfg,
+		logger:          log,
 	}
 }
 
@@ -41,18 +82,67 @@ func (s *dashboardService) GetDashboard(ctx context.Context, userId int) (*domai
 		s.logger.WarnContext(ctx, "failed to check invalidation flag", "userId", userId, "error", err)
 	}
 
-	if !invalidFlagExists {
-		var cachedResponse domain.DashboardResponse
-		if err := s.cacheService.Get(ctx, cacheKey, &cachedResponse); err == nil {
-			s.logger.DebugContext(ctx, "cache hit for dashboard", "userId", userId)
-			s.cacheService.RefreshTTL(ctx, cacheKey, s.config.Redis.CacheTTL)
-			return &cachedResponse, nil
-		}
-	} else {
+	if invalidFlagExists {
 		s.logger.DebugContext(ctx, "dashboard invalidation flag found, refreshing from RPC", "userId", userId)
 		s.cacheService.Delete(ctx, invalidFlagKey)
+		dashboardCacheTotal.WithLabelValues("cache_miss").Inc()
+		metrics.ObserveCacheInvalidation(dashboardMetricsService)
+		return s.refresh(ctx, userId, cacheKey)
 	}
 
+	var envelope dashboardCacheEnvelope
+	if err := s.cacheService.Get(ctx, cacheKey, &envelope); err == nil {
+		if time.Now().Before(envelope.FreshUntil) {
+			dashboardCacheTotal.WithLabelValues("cache_fresh_hit").Inc()
+			metrics.ObserveCacheHit(dashboardMetricsService)
+			return &envelope.Response, nil
+		}
+
+		dashboardCacheTotal.WithLabelValues("cache_stale_hit").Inc()
+		metrics.ObserveCacheHit(dashboardMetricsService)
+		s.logger.DebugContext(ctx, "serving stale dashboard cache, refreshing in background", "userId", userId)
+		go s.refreshInBackground(userId, cacheKey)
+
+		resp := envelope.Response
+		return &resp, nil
+	}
+
+	dashboardCacheTotal.WithLabelValues("cache_miss").Inc()
+	metrics.ObserveCacheMiss(dashboardMetricsService)
+	return s.refresh(ctx, userId, cacheKey)
+}
+
+// refresh blocks until userId's dashboard has been fetched over RPC (or
+// joins an already in-flight fetch for the same userId) and caches it.
+func (s *dashboardService) refresh(ctx context.Context, userId int, cacheKey string) (*domain.DashboardResponse, error) {
+	v, shared, err := s.sf.Do(strconv.Itoa(userId), func() (interface{}, error) {
+		return s.loadAndCache(ctx, userId, cacheKey)
+	})
+	if shared {
+		dashboardCacheTotal.WithLabelValues("singleflight_shared").Inc()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return v.(*domain.DashboardResponse), nil
+}
+
+// refreshInBackground is refresh run from a goroutine serving a stale hit:
+// it gets its own context (the HTTP request that triggered it may finish,
+// or its deadline may elapse, well before the refresh does) bounded by the
+// RPC timeout instead of inheriting the caller's.
+func (s *dashboardService) refreshInBackground(userId int, cacheKey string) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.RabbitMQ.RPCTimeout)
+	defer cancel()
+
+	if _, err := s.refresh(ctx, userId, cacheKey); err != nil {
+		s.logger.WarnContext(ctx, "background dashboard refresh failed", "userId", userId, "error", err)
+	}
+}
+
+// loadAndCache is the actual RPC call plus cache write; refresh is what adds
+// the singleflight layer described on dashboardService.
+func (s *dashboardService) loadAndCache(ctx context.Context, userId int, cacheKey string) (*domain.DashboardResponse, error) {
 	request := domain.DashboardRequest{
 		UserID: userId,
 	}
@@ -61,26 +151,33 @@ func (s *dashboardService) GetDashboard(ctx context.Context, userId int) (*domai
 		return nil, fmt.Errorf("invalid request: %w", err)
 	}
 
-	response, err := s.rpcService.Call(
-		ctx,
-		s.config.RabbitMQ.Queues.DashboardRequest,
-		request,
-		s.config.RabbitMQ.RPCTimeout,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get dashboard data: %w", err)
+	// Translate the caller's own context deadline (if any) onto the client's
+	// response deadline, so a request that's about to be cancelled anyway
+	// degrades to a "limited" dashboard instead of surfacing as an error once
+	// ctx.Done() wins the race inside GetDashboard.
+	if dl, ok := ctx.Deadline(); ok {
+		s.dashboardClient.SetResponseDeadline(dl)
+	} else {
+		s.dashboardClient.SetResponseDeadline(time.Now().Add(s.config.RabbitMQ.RPCTimeout))
 	}
 
-	var result domain.DashboardResponse
-	if err := json.Unmarshal(response, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse dashboard response: %w", err)
+	resultPtr, err := s.dashboardClient.GetDashboard(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dashboard data: %w", err)
 	}
+	result := *resultPtr
 
 	if result.IsError() {
 		return nil, fmt.Errorf("dashboard service error: %s", result.GetMessage())
 	}
 
-	if err := s.cacheService.Set(ctx, cacheKey, result, s.config.Redis.CacheTTL); err != nil {
+	now := time.Now()
+	envelope := dashboardCacheEnvelope{
+		Response:   result,
+		FreshUntil: now.Add(s.config.Redis.CacheFreshTTL),
+		StaleUntil: now.Add(s.config.Redis.CacheStaleTTL),
+	}
+	if err := s.cacheService.Set(ctx, cacheKey, envelope, s.config.Redis.CacheStaleTTL); err != nil {
 		s.logger.WarnContext(ctx, "failed to cache dashboard", "userId", userId, "error", err)
 	}
 
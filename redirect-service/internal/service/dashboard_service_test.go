@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hoggir/re-path/redirect-service/internal/config"
+	"github.com/hoggir/re-path/redirect-service/internal/domain"
+)
+
+var errCacheMiss = errors.New("cache miss")
+
+// fakeDashboardCache is a minimal in-memory CacheService double: enough for
+// dashboardService's Get/Set/Exists/Delete use, with everything else
+// unimplemented since dashboardService never calls it.
+type fakeDashboardCache struct {
+	CacheService
+	values map[string][]byte
+	flags  map[string]bool
+}
+
+func newFakeDashboardCache() *fakeDashboardCache {
+	return &fakeDashboardCache{values: make(map[string][]byte), flags: make(map[string]bool)}
+}
+
+func (c *fakeDashboardCache) Get(ctx context.Context, key string, dest interface{}) error {
+	raw, ok := c.values[key]
+	if !ok {
+		return errCacheMiss
+	}
+	return json.Unmarshal(raw, dest)
+}
+
+func (c *fakeDashboardCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	c.values[key] = raw
+	return nil
+}
+
+func (c *fakeDashboardCache) Delete(ctx context.Context, key string) error {
+	delete(c.flags, key)
+	return nil
+}
+
+func (c *fakeDashboardCache) Exists(ctx context.Context, key string) (bool, error) {
+	return c.flags[key], nil
+}
+
+// fakeDashboardClient is a minimal DashboardClient double, counting calls so
+// tests can assert the RPC was (or wasn't) made and that concurrent misses
+// for the same user collapse into one.
+type fakeDashboardClient struct {
+	calls    int32
+	response domain.DashboardResponse
+	err      error
+	delay    time.Duration
+}
+
+func (f *fakeDashboardClient) GetDashboard(ctx context.Context, req domain.DashboardRequest) (*domain.DashboardResponse, error) {
+	atomic.AddInt32(&f.calls, 1)
+	if f.delay > 0 {
+		time.Sleep(f.delay)
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	resp := f.response
+	return &resp, nil
+}
+
+func (f *fakeDashboardClient) SetRequestDeadline(t time.Time)  {}
+func (f *fakeDashboardClient) SetResponseDeadline(t time.Time) {}
+
+func newTestDashboardService(client DashboardClient, cache CacheService) *dashboardService {
+	return &dashboardService{
+		dashboardClient: client,
+		cacheService:    cache,
+		cacheKeys:       NewCacheKeyGenerator(&config.Config{}),
+		config: &config.Config{
+			Redis:    config.RedisConfig{CacheFreshTTL: time.Hour, CacheStaleTTL: 2 * time.Hour},
+			RabbitMQ: config.RabbitMQConfig{RPCTimeout: time.Second},
+		},
+		logger: testLogger(),
+	}
+}
+
+func TestDashboardService_GetDashboard_CacheMissCallsRPC(t *testing.T) {
+	client := &fakeDashboardClient{response: domain.DashboardResponse{UserID: 7, Status: "success"}}
+	svc := newTestDashboardService(client, newFakeDashboardCache())
+
+	resp, err := svc.GetDashboard(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("GetDashboard() error = %v", err)
+	}
+	if !resp.IsSuccess() || resp.UserID != 7 {
+		t.Errorf("GetDashboard() = %+v, want a success response for user 7", resp)
+	}
+	if atomic.LoadInt32(&client.calls) != 1 {
+		t.Errorf("RPC calls = %d, want 1", client.calls)
+	}
+}
+
+func TestDashboardService_GetDashboard_FreshHitSkipsRPC(t *testing.T) {
+	client := &fakeDashboardClient{response: domain.DashboardResponse{UserID: 7, Status: "success"}}
+	svc := newTestDashboardService(client, newFakeDashboardCache())
+
+	if _, err := svc.GetDashboard(context.Background(), 7); err != nil {
+		t.Fatalf("first GetDashboard() error = %v", err)
+	}
+	if _, err := svc.GetDashboard(context.Background(), 7); err != nil {
+		t.Fatalf("second GetDashboard() error = %v", err)
+	}
+
+	if atomic.LoadInt32(&client.calls) != 1 {
+		t.Errorf("RPC calls = %d, want 1 (second call should be a fresh cache hit)", client.calls)
+	}
+}
+
+func TestDashboardService_GetDashboard_StaleHitServesStaleAndRefreshes(t *testing.T) {
+	client := &fakeDashboardClient{response: domain.DashboardResponse{UserID: 7, Status: "success"}}
+	cache := newFakeDashboardCache()
+	svc := newTestDashboardService(client, cache)
+	svc.config.Redis.CacheFreshTTL = -time.Second // already stale the instant it's written
+
+	resp, err := svc.GetDashboard(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("GetDashboard() error = %v", err)
+	}
+	if !resp.IsSuccess() {
+		t.Fatalf("first GetDashboard() = %+v, want a success response", resp)
+	}
+
+	// The entry written above is already stale, so this call should return
+	// it immediately and kick off a background refresh rather than block.
+	resp, err = svc.GetDashboard(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("GetDashboard() on stale entry error = %v", err)
+	}
+	if !resp.IsSuccess() {
+		t.Errorf("GetDashboard() on stale entry = %+v, want the stale success response", resp)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&client.calls) < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if calls := atomic.LoadInt32(&client.calls); calls != 2 {
+		t.Errorf("RPC calls = %d, want 2 (initial load + background refresh)", calls)
+	}
+}
+
+func TestDashboardService_GetDashboard_ConcurrentMissesCollapseViaSingleflight(t *testing.T) {
+	client := &fakeDashboardClient{
+		response: domain.DashboardResponse{UserID: 7, Status: "success"},
+		delay:    20 * time.Millisecond,
+	}
+	svc := newTestDashboardService(client, newFakeDashboardCache())
+
+	const concurrency = 10
+	done := make(chan error, concurrency)
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			_, err := svc.GetDashboard(context.Background(), 7)
+			done <- err
+		}()
+	}
+	for i := 0; i < concurrency; i++ {
+		if err := <-done; err != nil {
+			t.Errorf("GetDashboard() error = %v", err)
+		}
+	}
+
+	if calls := atomic.LoadInt32(&client.calls); calls != 1 {
+		t.Errorf("RPC calls = %d, want 1 (all concurrent misses should share one RPC)", calls)
+	}
+}
+
+func TestDashboardService_GetDashboard_InvalidationFlagForcesRefresh(t *testing.T) {
+	client := &fakeDashboardClient{response: domain.DashboardResponse{UserID: 7, Status: "success"}}
+	cache := newFakeDashboardCache()
+	svc := newTestDashboardService(client, cache)
+
+	if _, err := svc.GetDashboard(context.Background(), 7); err != nil {
+		t.Fatalf("first GetDashboard() error = %v", err)
+	}
+
+	cache.flags[svc.cacheKeys.DashboardInvalidationFlag(7)] = true
+
+	if _, err := svc.GetDashboard(context.Background(), 7); err != nil {
+		t.Fatalf("second GetDashboard() error = %v", err)
+	}
+
+	if calls := atomic.LoadInt32(&client.calls); calls != 2 {
+		t.Errorf("RPC calls = %d, want 2 (invalidation flag should force a refresh)", calls)
+	}
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/hoggir/re-path/redirect-service/internal/domain"
+)
+
+// elasticsearchBulkClient posts a batch to the Elasticsearch _bulk API as
+// newline-delimited JSON: one "index" action line per document, each
+// followed by its source line. It only implements what the click-event
+// ingester needs - no client library dependency, mirroring how jwksCache
+// talks to its IdP directly over net/http.
+type elasticsearchBulkClient struct {
+	bulkURL string
+	client  *http.Client
+}
+
+func newElasticsearchBulkClient(bulkURL string, timeout time.Duration) *elasticsearchBulkClient {
+	return &elasticsearchBulkClient{
+		bulkURL: bulkURL,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// bulkItemError is one failed action from an Elasticsearch _bulk response,
+// reported so a caller can retry just the documents that actually failed
+// instead of re-sending the whole (mostly-successful) batch.
+type bulkItemError struct {
+	Index  int
+	Status int
+	Reason string
+}
+
+// Bulk indexes events into their respective PayloadElasticClick.IndexType
+// indices and returns the per-item failures from the response, if any. A
+// non-nil error means the request itself failed (network, non-2xx status);
+// a non-empty []bulkItemError alongside a nil error means the request
+// succeeded but some documents within it were rejected.
+func (c *elasticsearchBulkClient) Bulk(ctx context.Context, events []domain.PayloadElasticClick) ([]bulkItemError, error) {
+	if len(events) == 0 {
+		return nil, nil
+	}
+
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, evt := range events {
+		action := map[string]interface{}{
+			"index": map[string]string{"_index": evt.IndexType},
+		}
+		if err := enc.Encode(action); err != nil {
+			return nil, fmt.Errorf("elasticsearch bulk: encode action: %w", err)
+		}
+		if err := enc.Encode(evt.Data); err != nil {
+			return nil, fmt.Errorf("elasticsearch bulk: encode document: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.bulkURL, &body)
+	if err != nil {
+		return nil, fmt.Errorf("elasticsearch bulk: build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("elasticsearch bulk: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("elasticsearch bulk: status %d: %s", resp.StatusCode, respBody)
+	}
+
+	var parsed bulkResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("elasticsearch bulk: decode response: %w", err)
+	}
+
+	if !parsed.Errors {
+		return nil, nil
+	}
+
+	var failures []bulkItemError
+	for i, item := range parsed.Items {
+		result := item.Index
+		if result.Error == nil {
+			continue
+		}
+		failures = append(failures, bulkItemError{
+			Index:  i,
+			Status: result.Status,
+			Reason: result.Error.Reason,
+		})
+	}
+	return failures, nil
+}
+
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []struct {
+		Index bulkResponseItem `json:"index"`
+	} `json:"items"`
+}
+
+type bulkResponseItem struct {
+	Status int `json:"status"`
+	Error  *struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"error,omitempty"`
+}
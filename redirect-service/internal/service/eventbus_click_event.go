@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hoggir/re-path/redirect-service/internal/config"
+	"github.com/hoggir/re-path/redirect-service/internal/domain"
+	"github.com/hoggir/re-path/redirect-service/internal/eventbus"
+	"github.com/hoggir/re-path/redirect-service/internal/logger"
+)
+
+// eventBusClickPublisher implements RabbitMQService on top of an
+// eventbus.EventBus, so NewRabbitMQService can hand back something that
+// publishes over Kafka without PublishClickEvent's caller knowing the
+// difference.
+type eventBusClickPublisher struct {
+	bus    eventbus.EventBus
+	cfg    *config.Config
+	logger logger.Logger
+}
+
+func newEventBusClickPublisher(bus eventbus.EventBus, cfg *config.Config, log logger.Logger) RabbitMQService {
+	return &eventBusClickPublisher{bus: bus, cfg: cfg, logger: log}
+}
+
+func (p *eventBusClickPublisher) PublishClickEvent(ctx context.Context, payload []byte) error {
+	topic := p.cfg.Kafka.ClickEventsTopic
+
+	if err := p.bus.Publish(ctx, topic, payload); err != nil {
+		clickEventsPublishedTotal.WithLabelValues("error").Inc()
+		return err
+	}
+
+	clickEventsPublishedTotal.WithLabelValues("ok").Inc()
+	p.logger.DebugContext(ctx, "published click event", "topic", topic, "bytes", len(payload))
+	return nil
+}
+
+// eventBusClickEventConsumer implements ClickEventConsumer on top of an
+// eventbus.EventBus. Unlike clickEventConsumer it has no x-death header to
+// count retries from, so a poison message is published straight to
+// cfg.Kafka.ClickEventsDLQTopic on its first failure rather than bounced
+// through a retry queue. A message the ingester fails to accept is never
+// dead-lettered: it is Nack(true)'d, and Kafka's offset-based redelivery
+// gives the "don't commit, retry on restart" behavior for that case.
+type eventBusClickEventConsumer struct {
+	bus          eventbus.EventBus
+	ingester     ClickEventIngester
+	geoIPService GeoIPService
+	botDetector  BotDetector
+	cfg          *config.Config
+	logger       logger.Logger
+
+	cancel context.CancelFunc
+	doneCh chan struct{}
+}
+
+func newEventBusClickEventConsumer(bus eventbus.EventBus, ingester ClickEventIngester, geoIPService GeoIPService, botDetector BotDetector, cfg *config.Config, log logger.Logger) ClickEventConsumer {
+	return &eventBusClickEventConsumer{
+		bus:          bus,
+		ingester:     ingester,
+		geoIPService: geoIPService,
+		botDetector:  botDetector,
+		cfg:          cfg,
+		logger:       log,
+		doneCh:       make(chan struct{}),
+	}
+}
+
+func (c *eventBusClickEventConsumer) Run(ctx context.Context) {
+	defer close(c.doneCh)
+
+	runCtx, cancel := context.WithCancel(ctx)
+	c.cancel = cancel
+
+	deliveries, err := c.bus.Subscribe(runCtx, c.cfg.Kafka.ClickEventsTopic, c.cfg.Kafka.ClickEventsConsumerGroup)
+	if err != nil {
+		c.logger.Error("failed to start click event consumer", "topic", c.cfg.Kafka.ClickEventsTopic, "error", err)
+		return
+	}
+
+	for delivery := range deliveries {
+		c.handle(context.Background(), delivery)
+	}
+}
+
+func (c *eventBusClickEventConsumer) Shutdown(ctx context.Context) error {
+	if c.cancel != nil {
+		c.cancel()
+	}
+
+	select {
+	case <-c.doneCh:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *eventBusClickEventConsumer) handle(ctx context.Context, delivery eventbus.Delivery) {
+	var msg domain.ClickEventMessage
+	if err := json.Unmarshal(delivery.Body, &msg); err != nil {
+		c.logger.Error("failed to unmarshal click event, dead-lettering", "error", err)
+		clickEventsConsumedTotal.WithLabelValues("poison").Inc()
+		c.deadLetter(ctx, delivery.Body)
+		if err := delivery.Ack(); err != nil {
+			c.logger.Error("failed to ack dead-lettered click event", "error", err)
+		}
+		return
+	}
+
+	evt := enrichClickEvent(ctx, msg, c.geoIPService, c.botDetector, c.logger)
+
+	if err := c.ingester.Enqueue(ctx, evt); err != nil {
+		c.logger.Warn("failed to enqueue click event for ingestion, retrying", "shortCode", msg.ShortCode, "error", err)
+		clickEventsConsumedTotal.WithLabelValues("retry").Inc()
+		if nackErr := delivery.Nack(true); nackErr != nil {
+			c.logger.Error("failed to nack click event for redelivery", "error", nackErr)
+		}
+		return
+	}
+
+	clickEventsConsumedTotal.WithLabelValues("ok").Inc()
+	if err := delivery.Ack(); err != nil {
+		c.logger.Error("failed to ack click event", "error", err)
+	}
+}
+
+// deadLetter publishes body onto cfg.Kafka.ClickEventsDLQTopic; handle acks
+// the original delivery either way - a publish failure here would otherwise
+// leave a poison message stuck redelivering forever.
+func (c *eventBusClickEventConsumer) deadLetter(ctx context.Context, body []byte) {
+	clickEventsDeadLetteredTotal.Inc()
+	if err := c.bus.Publish(ctx, c.cfg.Kafka.ClickEventsDLQTopic, body); err != nil {
+		c.logger.Error("failed to publish click event to DLQ topic", "topic", c.cfg.Kafka.ClickEventsDLQTopic, "error", err)
+	}
+}
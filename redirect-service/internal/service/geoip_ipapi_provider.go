@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/hoggir/re-path/redirect-service/internal/config"
+	"github.com/hoggir/re-path/redirect-service/internal/domain"
+)
+
+// ipAPIProvider looks up geolocations against the free http://ip-api.com
+// service. It has strict per-IP rate limits, so it is meant to be used behind
+// CacheService, or as a fallback when a local database provider is unavailable.
+type ipAPIProvider struct {
+	client *http.Client
+	config *config.Config
+}
+
+func newIPAPIProvider(cfg *config.Config) GeoIPProvider {
+	return &ipAPIProvider{
+		client: &http.Client{
+			Timeout: cfg.Service.GeoIPTimeout,
+		},
+		config: cfg,
+	}
+}
+
+func (p *ipAPIProvider) Name() string {
+	return "ipapi"
+}
+
+func (p *ipAPIProvider) Lookup(ctx context.Context, ip string) (*domain.GeoLocation, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, p.config.Service.GeoIPTimeout)
+	defer cancel()
+
+	url := fmt.Sprintf("http://ip-api.com/json/%s?fields=status,message,country,countryCode,region,regionName,city,zip,lat,lon,timezone,isp,org,as,query", ip)
+
+	req, err := http.NewRequestWithContext(reqCtx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch geolocation: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("geolocation API returned status: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var apiResponse struct {
+		Status      string  `json:"status"`
+		Message     string  `json:"message,omitempty"`
+		Country     string  `json:"country"`
+		CountryCode string  `json:"countryCode"`
+		Region      string  `json:"region"`
+		RegionName  string  `json:"regionName"`
+		City        string  `json:"city"`
+		Zip         string  `json:"zip"`
+		Lat         float64 `json:"lat"`
+		Lon         float64 `json:"lon"`
+		Timezone    string  `json:"timezone"`
+		ISP         string  `json:"isp"`
+		Org         string  `json:"org"`
+		AS          string  `json:"as"`
+		Query       string  `json:"query"`
+	}
+
+	if err := json.Unmarshal(body, &apiResponse); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if apiResponse.Status != "success" {
+		return nil, fmt.Errorf("geolocation API error: %s", apiResponse.Message)
+	}
+
+	return &domain.GeoLocation{
+		Country:     apiResponse.Country,
+		CountryCode: apiResponse.CountryCode,
+		Region:      apiResponse.Region,
+		RegionName:  apiResponse.RegionName,
+		City:        apiResponse.City,
+		Zip:         apiResponse.Zip,
+		Lat:         apiResponse.Lat,
+		Lon:         apiResponse.Lon,
+		Timezone:    apiResponse.Timezone,
+		ISP:         apiResponse.ISP,
+		Org:         apiResponse.Org,
+		AS:          apiResponse.AS,
+		Query:       apiResponse.Query,
+	}, nil
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/hoggir/re-path/redirect-service/internal/config"
+	"github.com/hoggir/re-path/redirect-service/internal/domain"
+	"github.com/hoggir/re-path/redirect-service/internal/logger"
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// maxmindProvider memory-maps a MaxMind/IP2Location-format .mmdb file at
+// startup so lookups never hit the network. The reader is safe for concurrent
+// use by multiple goroutines.
+type maxmindProvider struct {
+	reader *maxminddb.Reader
+	logger logger.Logger
+}
+
+type maxmindCityRecord struct {
+	Country struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"country"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	Subdivisions []struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"subdivisions"`
+	Location struct {
+		Latitude  float64 `maxminddb:"latitude"`
+		Longitude float64 `maxminddb:"longitude"`
+		TimeZone  string  `maxminddb:"time_zone"`
+	} `maxminddb:"location"`
+}
+
+// newMaxmindProvider opens and memory-maps the database at path. The caller
+// should fall back to another provider if this returns an error, e.g. because
+// GeoIPDatabasePath isn't configured yet in this environment.
+func newMaxmindProvider(path string, log logger.Logger) (GeoIPProvider, error) {
+	if path == "" {
+		return nil, fmt.Errorf("maxmind: database path is not configured")
+	}
+
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("maxmind: failed to open database %q: %w", path, err)
+	}
+
+	log.Info("MaxMind GeoIP database loaded", "path", path, "buildEpoch", reader.Metadata.BuildEpoch)
+
+	return &maxmindProvider{reader: reader, logger: log}, nil
+}
+
+func (p *maxmindProvider) Name() string {
+	return "maxmind"
+}
+
+func (p *maxmindProvider) Lookup(ctx context.Context, ip string) (*domain.GeoLocation, error) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return nil, fmt.Errorf("maxmind: invalid IP address %q", ip)
+	}
+
+	var record maxmindCityRecord
+	if err := p.reader.Lookup(parsed, &record); err != nil {
+		return nil, fmt.Errorf("maxmind: lookup failed: %w", err)
+	}
+
+	regionName := ""
+	if len(record.Subdivisions) > 0 {
+		regionName = record.Subdivisions[0].Names["en"]
+	}
+
+	return &domain.GeoLocation{
+		Country:     record.Country.Names["en"],
+		CountryCode: record.Country.ISOCode,
+		RegionName:  regionName,
+		City:        record.City.Names["en"],
+		Lat:         record.Location.Latitude,
+		Lon:         record.Location.Longitude,
+		Timezone:    record.Location.TimeZone,
+		Query:       ip,
+	}, nil
+}
+
+func (p *maxmindProvider) Close() error {
+	return p.reader.Close()
+}
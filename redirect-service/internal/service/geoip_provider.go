@@ -0,0 +1,22 @@
+package service
+
+import (
+	"context"
+
+	"github.com/hoggir/re-path/redirect-service/internal/domain"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// GeoIPProvider resolves an IP address to a GeoLocation. Implementations are
+// selected via Service.GeoIPProvider and may or may not need the Redis cache
+// layer in front of them (a local database lookup has no I/O to save).
+type GeoIPProvider interface {
+	Name() string
+	Lookup(ctx context.Context, ip string) (*domain.GeoLocation, error)
+}
+
+var geoIPLookupsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "repath_geoip_lookups_total",
+	Help: "Total GeoIP lookups, partitioned by provider and outcome.",
+}, []string{"provider", "outcome"})
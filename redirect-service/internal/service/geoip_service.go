@@ -2,10 +2,6 @@ package service
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
 
 	"github.com/hoggir/re-path/redirect-service/internal/config"
 	"github.com/hoggir/re-path/redirect-service/internal/domain"
@@ -16,31 +12,42 @@ type GeoIPService interface {
 	GetLocation(ctx context.Context, ip string) (*domain.GeoLocation, error)
 }
 
+type geoIPService struct {
+	provider     GeoIPProvider
+	fallback     GeoIPProvider
+	cacheService CacheService
+	cacheKeys    *CacheKeyGenerator
+	config       *config.Config
+	logger       logger.Logger
+}
+
+// NewGeoIPService selects a GeoIPProvider based on Service.GeoIPProvider. Local
+// database backends (maxmind, ip2location) do their own lookup with no I/O, so
+// the Redis cache becomes a defense-in-depth layer rather than a requirement;
+// the ipapi provider always needs it to stay under the upstream rate limit.
+// If a local database provider fails to load, we fall back to ipapi so the
+// service still starts.
 func NewGeoIPService(
 	cacheService CacheService,
 	cacheKeys *CacheKeyGenerator,
 	cfg *config.Config,
 	log logger.Logger,
 ) GeoIPService {
-	return &geoIPService{
-		client: &http.Client{
-			Timeout: cfg.Service.GeoIPTimeout,
-		},
-		cacheService: cacheService,
-		cacheKeys:    cacheKeys,
-		config:       cfg,
-		logger:       log,
+	ipAPI := newIPAPIProvider(cfg)
+
+	switch cfg.Service.GeoIPProvider {
+	case "maxmind", "ip2location":
+		provider, err := newMaxmindProvider(cfg.Service.GeoIPDatabasePath, log)
+		if err != nil {
+			log.Warn("failed to load local GeoIP database, falling back to ipapi", "provider", cfg.Service.GeoIPProvider, "error", err)
+			return &geoIPService{provider: ipAPI, cacheService: cacheService, cacheKeys: cacheKeys, config: cfg, logger: log}
+		}
+		return &geoIPService{provider: provider, fallback: ipAPI, cacheService: cacheService, cacheKeys: cacheKeys, config: cfg, logger: log}
+	default:
+		return &geoIPService{provider: ipAPI, cacheService: cacheService, cacheKeys: cacheKeys, config: cfg, logger: log}
 	}
 }
 
-type geoIPService struct {
-	client       *http.Client
-	cacheService CacheService
-	cacheKeys    *CacheKeyGenerator
-	config       *config.Config
-	logger       logger.Logger
-}
-
 func (s *geoIPService) GetLocation(ctx context.Context, ip string) (*domain.GeoLocation, error) {
 	if isLocalOrPrivateIP(ip) {
 		s.logger.DebugContext(ctx, "IP is localhost or private, returning default location", "ip", ip)
@@ -53,78 +60,23 @@ func (s *geoIPService) GetLocation(ctx context.Context, ip string) (*domain.GeoL
 
 	cacheKey := s.cacheKeys.GeoIP(ip)
 	var location domain.GeoLocation
-	err := s.cacheService.Get(ctx, cacheKey, &location)
-	if err == nil {
-		s.cacheService.RefreshTTL(ctx, cacheKey, s.config.Redis.CacheTTL)
+	// Geolocations rarely change, so the client-side cache is a natural fit here.
+	if err := s.cacheService.GetCached(ctx, cacheKey, &location, s.config.Redis.LocalCacheTTL); err == nil {
+		geoIPLookupsTotal.WithLabelValues(s.provider.Name(), "cache_hit").Inc()
 		return &location, nil
 	}
 
-	reqCtx, cancel := context.WithTimeout(ctx, s.config.Service.GeoIPTimeout)
-	defer cancel()
-
-	url := fmt.Sprintf("http://ip-api.com/json/%s?fields=status,message,country,countryCode,region,regionName,city,zip,lat,lon,timezone,isp,org,as,query", ip)
-
-	req, err := http.NewRequestWithContext(reqCtx, "GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	resp, err := s.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch geolocation: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("geolocation API returned status: %d", resp.StatusCode)
+	geoLocation, err := s.provider.Lookup(ctx, ip)
+	if err != nil && s.fallback != nil {
+		s.logger.WarnContext(ctx, "GeoIP provider lookup failed, trying fallback", "provider", s.provider.Name(), "ip", ip, "error", err)
+		geoLocation, err = s.fallback.Lookup(ctx, ip)
 	}
-
-	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		geoIPLookupsTotal.WithLabelValues(s.provider.Name(), "error").Inc()
+		return nil, err
 	}
 
-	var apiResponse struct {
-		Status      string  `json:"status"`
-		Message     string  `json:"message,omitempty"`
-		Country     string  `json:"country"`
-		CountryCode string  `json:"countryCode"`
-		Region      string  `json:"region"`
-		RegionName  string  `json:"regionName"`
-		City        string  `json:"city"`
-		Zip         string  `json:"zip"`
-		Lat         float64 `json:"lat"`
-		Lon         float64 `json:"lon"`
-		Timezone    string  `json:"timezone"`
-		ISP         string  `json:"isp"`
-		Org         string  `json:"org"`
-		AS          string  `json:"as"`
-		Query       string  `json:"query"`
-	}
-
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
-	}
-
-	if apiResponse.Status != "success" {
-		return nil, fmt.Errorf("geolocation API error: %s", apiResponse.Message)
-	}
-
-	geoLocation := &domain.GeoLocation{
-		Country:     apiResponse.Country,
-		CountryCode: apiResponse.CountryCode,
-		Region:      apiResponse.Region,
-		RegionName:  apiResponse.RegionName,
-		City:        apiResponse.City,
-		Zip:         apiResponse.Zip,
-		Lat:         apiResponse.Lat,
-		Lon:         apiResponse.Lon,
-		Timezone:    apiResponse.Timezone,
-		ISP:         apiResponse.ISP,
-		Org:         apiResponse.Org,
-		AS:          apiResponse.AS,
-		Query:       apiResponse.Query,
-	}
+	geoIPLookupsTotal.WithLabelValues(s.provider.Name(), "miss").Inc()
 
 	if err := s.cacheService.Set(ctx, cacheKey, geoLocation, s.config.Redis.CacheTTL); err != nil {
 		s.logger.WarnContext(ctx, "failed to cache location for IP", "ip", ip, "error", err)
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hoggir/re-path/redirect-service/internal/config"
+	"github.com/hoggir/re-path/redirect-service/internal/logger"
+)
+
+// GeoIPUpdater downloads a fresh copy of the free MaxMind/IP2Location database
+// on a daily schedule when Service.GeoIPAutoUpdate is enabled, verifies its
+// checksum, and atomically swaps it into place so a running geoIPService can
+// pick it up on next restart.
+type GeoIPUpdater struct {
+	downloadURL  string
+	checksumURL  string
+	databasePath string
+	interval     time.Duration
+	client       *http.Client
+	logger       logger.Logger
+}
+
+// NewGeoIPUpdater reads its download/checksum URLs and target database path
+// from Service.GeoIP*; the caller is responsible for only starting Run when
+// Service.GeoIPAutoUpdate is enabled (see Server.startGeoIPUpdater).
+func NewGeoIPUpdater(cfg *config.Config, log logger.Logger) *GeoIPUpdater {
+	return &GeoIPUpdater{
+		downloadURL:  cfg.Service.GeoIPDownloadURL,
+		checksumURL:  cfg.Service.GeoIPChecksumURL,
+		databasePath: cfg.Service.GeoIPDatabasePath,
+		interval:     24 * time.Hour,
+		client:       &http.Client{Timeout: 2 * time.Minute},
+		logger:       log,
+	}
+}
+
+// Run blocks, performing an update immediately and then once per interval,
+// until ctx is cancelled.
+func (u *GeoIPUpdater) Run(ctx context.Context) {
+	ticker := time.NewTicker(u.interval)
+	defer ticker.Stop()
+
+	if err := u.updateOnce(ctx); err != nil {
+		u.logger.WarnContext(ctx, "GeoIP database update failed", "error", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := u.updateOnce(ctx); err != nil {
+				u.logger.WarnContext(ctx, "GeoIP database update failed", "error", err)
+			}
+		}
+	}
+}
+
+func (u *GeoIPUpdater) updateOnce(ctx context.Context) error {
+	wantChecksum, err := u.fetchChecksum(ctx)
+	if err != nil {
+		return fmt.Errorf("fetch checksum: %w", err)
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(u.databasePath), "geoip-*.mmdb.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	hasher := sha256.New()
+	if err := u.download(ctx, io.MultiWriter(tmpFile, hasher)); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("download database: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("close temp file: %w", err)
+	}
+
+	gotChecksum := hex.EncodeToString(hasher.Sum(nil))
+	if gotChecksum != wantChecksum {
+		return fmt.Errorf("checksum mismatch: got %s, want %s", gotChecksum, wantChecksum)
+	}
+
+	if err := os.Rename(tmpFile.Name(), u.databasePath); err != nil {
+		return fmt.Errorf("atomic swap into %s: %w", u.databasePath, err)
+	}
+
+	u.logger.Info("GeoIP database updated", "path", u.databasePath, "checksum", gotChecksum)
+	return nil
+}
+
+func (u *GeoIPUpdater) fetchChecksum(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.checksumURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	if err != nil {
+		return "", err
+	}
+
+	return string(body), nil
+}
+
+func (u *GeoIPUpdater) download(ctx context.Context, dst io.Writer) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.downloadURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	_, err = io.Copy(dst, resp.Body)
+	return err
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hoggir/re-path/redirect-service/internal/config"
+	"github.com/hoggir/re-path/redirect-service/internal/database"
+	"github.com/hoggir/re-path/redirect-service/internal/logger"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// DependencyStatus is one dependency's most recently checked reachability.
+type DependencyStatus struct {
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+// ReadinessReport is HealthService's cached view of the whole process:
+// Ready is false if any dependency is unreachable, or once Shutdown has been
+// called, whichever comes first.
+type ReadinessReport struct {
+	Ready        bool               `json:"ready"`
+	Dependencies []DependencyStatus `json:"dependencies"`
+}
+
+// HealthService tracks MongoDB/Redis/RabbitMQ reachability for the /readyz
+// probe. Pings never happen on the request path: Run refreshes a cached
+// ReadinessReport on config.Health.CheckInterval, and Readiness just reads
+// it, so a slow or wedged dependency can't make every request to /readyz
+// itself hang.
+type HealthService interface {
+	// Run refreshes the cached report every config.Health.CheckInterval
+	// until ctx is done.
+	Run(ctx context.Context)
+	// Readiness returns the most recently cached report.
+	Readiness() ReadinessReport
+	// Shutdown flips the cached report to not-ready immediately, ahead of
+	// the next scheduled refresh. Call this at the start of graceful
+	// shutdown so a load balancer stops routing new traffic here before
+	// httpServer.Shutdown starts rejecting it outright.
+	Shutdown()
+}
+
+type healthService struct {
+	mongoDB  *database.MongoDB
+	redis    *database.Redis
+	rabbitmq *database.RabbitMQ
+	config   *config.Config
+	logger   logger.Logger
+
+	mu           sync.RWMutex
+	report       ReadinessReport
+	shuttingDown atomic.Bool
+}
+
+func NewHealthService(mongoDB *database.MongoDB, redis *database.Redis, rabbitmq *database.RabbitMQ, cfg *config.Config, log logger.Logger) HealthService {
+	s := &healthService{
+		mongoDB:  mongoDB,
+		redis:    redis,
+		rabbitmq: rabbitmq,
+		config:   cfg,
+		logger:   log,
+	}
+	// Seed the cache synchronously so the very first /readyz call (which may
+	// land before Run's first tick) doesn't report ready=false for want of
+	// ever having checked anything.
+	s.refresh(context.Background())
+	return s
+}
+
+func (s *healthService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.Health.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.refresh(ctx)
+		}
+	}
+}
+
+func (s *healthService) refresh(ctx context.Context) {
+	pingCtx, cancel := context.WithTimeout(ctx, s.config.Health.PingTimeout)
+	defer cancel()
+
+	deps := []DependencyStatus{
+		s.pingMongoDB(pingCtx),
+		s.pingRedis(pingCtx),
+		s.pingRabbitMQ(),
+	}
+
+	ready := !s.shuttingDown.Load()
+	for _, dep := range deps {
+		if !dep.Healthy {
+			ready = false
+		}
+	}
+	if !ready {
+		s.logger.WarnContext(ctx, "readiness check failing", "dependencies", deps)
+	}
+
+	s.mu.Lock()
+	s.report = ReadinessReport{Ready: ready, Dependencies: deps}
+	s.mu.Unlock()
+}
+
+func (s *healthService) pingMongoDB(ctx context.Context) DependencyStatus {
+	if err := s.mongoDB.Client.Ping(ctx, readpref.Primary()); err != nil {
+		return DependencyStatus{Name: "mongodb", Error: err.Error()}
+	}
+	return DependencyStatus{Name: "mongodb", Healthy: true}
+}
+
+func (s *healthService) pingRedis(ctx context.Context) DependencyStatus {
+	if err := s.redis.Client.Do(ctx, s.redis.Client.B().Ping().Build()).Error(); err != nil {
+		return DependencyStatus{Name: "redis", Error: err.Error()}
+	}
+	return DependencyStatus{Name: "redis", Healthy: true}
+}
+
+// pingRabbitMQ checks the connection's reported state rather than
+// round-tripping a request, so a readiness tick never waits on the broker.
+// IsConnected reflects whatever connection database.RabbitMQ's supervisor
+// currently holds, so a mid-reconnect gap here is reported rather than
+// hidden.
+func (s *healthService) pingRabbitMQ() DependencyStatus {
+	if !s.rabbitmq.IsConnected() {
+		return DependencyStatus{Name: "rabbitmq", Error: "connection closed"}
+	}
+	return DependencyStatus{Name: "rabbitmq", Healthy: true}
+}
+
+func (s *healthService) Readiness() ReadinessReport {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.report
+}
+
+func (s *healthService) Shutdown() {
+	s.shuttingDown.Store(true)
+
+	s.mu.Lock()
+	s.report.Ready = false
+	s.mu.Unlock()
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hoggir/re-path/redirect-service/internal/config"
+	"github.com/hoggir/re-path/redirect-service/internal/logger"
+)
+
+// jwk is a single entry of a JWKS document, covering the RSA and EC fields
+// jwtService needs; anything else (e.g. "use", key ops) is ignored.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwksDocument struct {
+	Keys []jwk `json:"keys"`
+}
+
+// jwksCache fetches a remote JWKS document and caches its keys by kid,
+// refreshing on a jittered background interval and, on an unknown kid,
+// lazily - rate-limited so a burst of bad/rotating kids can't turn into a
+// fetch storm against the IdP.
+type jwksCache struct {
+	url    string
+	client *http.Client
+	logger logger.Logger
+	config *config.Config
+
+	mu   sync.RWMutex
+	keys map[string]interface{}
+
+	missMu        sync.Mutex
+	lastMissFetch time.Time
+}
+
+func newJWKSCache(cfg *config.Config, log logger.Logger) *jwksCache {
+	return &jwksCache{
+		url:    cfg.JWT.JWKSURL,
+		client: &http.Client{Timeout: cfg.Service.ExternalAPITimeout},
+		logger: log,
+		config: cfg,
+		keys:   make(map[string]interface{}),
+	}
+}
+
+// Run fetches the JWKS once immediately, then keeps refreshing it on
+// JWKSRefreshInterval plus a random jitter up to JWKSRefreshJitter, until ctx
+// is done.
+func (c *jwksCache) Run(ctx context.Context) {
+	if err := c.refresh(ctx); err != nil {
+		c.logger.Error("initial JWKS fetch failed", "url", c.url, "error", err)
+	}
+
+	for {
+		jitter := time.Duration(rand.Int63n(int64(c.config.JWT.JWKSRefreshJitter) + 1))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(c.config.JWT.JWKSRefreshInterval + jitter):
+			if err := c.refresh(ctx); err != nil {
+				c.logger.Warn("periodic JWKS refresh failed", "url", c.url, "error", err)
+			}
+		}
+	}
+}
+
+func (c *jwksCache) refresh(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("jwks endpoint returned status %d", resp.StatusCode)
+	}
+
+	var doc jwksDocument
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return err
+	}
+
+	keys := make(map[string]interface{}, len(doc.Keys))
+	for _, k := range doc.Keys {
+		key, err := k.publicKey()
+		if err != nil {
+			c.logger.Warn("skipping unparseable JWKS entry", "kid", k.Kid, "error", err)
+			continue
+		}
+		keys[k.Kid] = key
+	}
+
+	c.mu.Lock()
+	c.keys = keys
+	c.mu.Unlock()
+
+	c.logger.Info("JWKS refreshed", "url", c.url, "keyCount", len(keys))
+	return nil
+}
+
+// Key returns the public key for kid. On a cache miss it refetches the whole
+// document - at most once per JWKSMissRefetchCooldown - to pick up a key
+// that rotated in between scheduled refreshes.
+func (c *jwksCache) Key(ctx context.Context, kid string) (interface{}, bool) {
+	c.mu.RLock()
+	key, ok := c.keys[kid]
+	c.mu.RUnlock()
+	if ok {
+		return key, true
+	}
+
+	c.missMu.Lock()
+	shouldFetch := time.Since(c.lastMissFetch) > c.config.JWT.JWKSMissRefetchCooldown
+	if shouldFetch {
+		c.lastMissFetch = time.Now()
+	}
+	c.missMu.Unlock()
+
+	if !shouldFetch {
+		return nil, false
+	}
+
+	if err := c.refresh(ctx); err != nil {
+		c.logger.Warn("on-miss JWKS refetch failed", "kid", kid, "error", err)
+		return nil, false
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	key, ok = c.keys[kid]
+	return key, ok
+}
+
+func (k jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		nBytes, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("decode n: %w", err)
+		}
+		eBytes, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("decode e: %w", err)
+		}
+
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(nBytes),
+			E: int(new(big.Int).SetBytes(eBytes).Int64()),
+		}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		default:
+			return nil, fmt.Errorf("unsupported EC curve %q", k.Crv)
+		}
+
+		xBytes, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("decode x: %w", err)
+		}
+		yBytes, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("decode y: %w", err)
+		}
+
+		return &ecdsa.PublicKey{
+			Curve: curve,
+			X:     new(big.Int).SetBytes(xBytes),
+			Y:     new(big.Int).SetBytes(yBytes),
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
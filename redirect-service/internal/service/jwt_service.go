@@ -1,26 +1,83 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/hoggir/re-path/redirect-service/internal/config"
+	"github.com/hoggir/re-path/redirect-service/internal/database"
 	"github.com/hoggir/re-path/redirect-service/internal/domain"
+	"github.com/hoggir/re-path/redirect-service/internal/logger"
+	"github.com/redis/rueidis"
 )
 
+// errUnsupportedSigningMethod and errUnknownSigningKey let keyFunc report
+// *why* no key was found, so ValidateToken can map them to
+// domain.ErrInvalidSigningKey instead of the catch-all ErrInvalidToken.
+var (
+	errUnsupportedSigningMethod = errors.New("jwt: signing method not permitted")
+	errUnknownSigningKey        = errors.New("jwt: no verification key found for token")
+)
+
+// ProviderMetadata describes which issuer/verification method this service
+// authenticates tokens against, so callers can log it without reaching into
+// jwtService internals.
+type ProviderMetadata struct {
+	Issuer   string
+	Audience string
+	JWKSURL  string
+}
+
 type JWTService interface {
-	ValidateToken(tokenString string) (*JWTClaims, error)
+	// ValidateToken verifies tokenString's signature, expiry, issuer/audience,
+	// then checks it hasn't been revoked (by jti or by a token_version bump)
+	// - see checkRevocation.
+	ValidateToken(ctx context.Context, tokenString string) (*JWTClaims, error)
+	// IssueTokenPair mints a new access+refresh pair for userID. Refresh
+	// tokens are opaque (their value doubles as the jti stored in Redis);
+	// access tokens are signed HMAC JWTs, so issuance only works in static
+	// secret mode - see jwt_session.go.
+	IssueTokenPair(ctx context.Context, userID int, email, role string) (*TokenPair, error)
+	// RefreshTokenPair redeems refreshToken for a new pair, rotating the old
+	// one out. Redeeming the same token again within JWT.RefreshRotationGrace
+	// returns the same pair instead of failing, so a client retrying a
+	// dropped response doesn't get logged out by its own retry.
+	RefreshTokenPair(ctx context.Context, refreshToken string) (*TokenPair, error)
+	// Logout revokes accessJTI immediately and every refresh token
+	// outstanding for userID, and bumps userID's token_version so every
+	// access token already issued to them stops validating too.
+	Logout(ctx context.Context, userID int, accessJTI string) error
+	// Metadata returns the issuer/audience/JWKS configuration tokens are
+	// verified against.
+	Metadata() ProviderMetadata
+	// Run starts the background JWKS refresh loop; a no-op when JWT.JWKSURL
+	// isn't configured (static HMAC secret mode).
+	Run(ctx context.Context)
 }
 
 type jwtService struct {
-	config *config.Config
+	config     *config.Config
+	logger     logger.Logger
+	jwks       *jwksCache
+	allowedAlg map[string]bool
+	redis      *database.Redis
+	cacheKeys  *CacheKeyGenerator
 }
 
 type JWTClaims struct {
 	Sub   interface{} `json:"sub"`
 	Email string      `json:"email"`
 	Role  string      `json:"role"`
+	// TokenVersion is compared against CacheKeyGenerator.TokenVersion(userID)
+	// on every validation; a token minted before the counter was last
+	// bumped is treated as revoked. Absent (zero-value) on tokens this
+	// service didn't mint itself, which validates fine against a counter
+	// that was never bumped.
+	TokenVersion int `json:"token_version"`
 	jwt.RegisteredClaims
 }
 
@@ -51,30 +108,145 @@ func (c *JWTClaims) GetUserIDAsInt() int {
 	}
 }
 
-func NewJWTService(cfg *config.Config) JWTService {
-	return &jwtService{
-		config: cfg,
+func NewJWTService(cfg *config.Config, redis *database.Redis, cacheKeys *CacheKeyGenerator, log logger.Logger) JWTService {
+	s := &jwtService{
+		config:     cfg,
+		logger:     log,
+		allowedAlg: parseAllowedAlgorithms(cfg.JWT.AllowedAlgorithms),
+		redis:      redis,
+		cacheKeys:  cacheKeys,
+	}
+
+	if cfg.JWT.JWKSURL != "" {
+		s.jwks = newJWKSCache(cfg, log)
 	}
+
+	return s
 }
 
-func (s *jwtService) ValidateToken(tokenString string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, domain.ErrInvalidSigningKey
+func parseAllowedAlgorithms(raw string) map[string]bool {
+	allowed := make(map[string]bool)
+	for _, alg := range strings.Split(raw, ",") {
+		if alg = strings.TrimSpace(alg); alg != "" {
+			allowed[alg] = true
 		}
-		return []byte(s.config.JWT.Secret), nil
-	})
+	}
+	return allowed
+}
+
+func (s *jwtService) Run(ctx context.Context) {
+	if s.jwks == nil {
+		return
+	}
+	s.jwks.Run(ctx)
+}
+
+func (s *jwtService) Metadata() ProviderMetadata {
+	return ProviderMetadata{
+		Issuer:   s.config.JWT.Issuer,
+		Audience: s.config.JWT.Audience,
+		JWKSURL:  s.config.JWT.JWKSURL,
+	}
+}
+
+func (s *jwtService) ValidateToken(ctx context.Context, tokenString string) (*JWTClaims, error) {
+	var parserOpts []jwt.ParserOption
+	if s.config.JWT.Issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(s.config.JWT.Issuer))
+	}
+	if s.config.JWT.Audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(s.config.JWT.Audience))
+	}
 
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, s.keyFunc, parserOpts...)
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
 			return nil, domain.ErrTokenExpired.Wrap(err)
 		}
+		if errors.Is(err, errUnsupportedSigningMethod) || errors.Is(err, errUnknownSigningKey) {
+			return nil, domain.ErrInvalidSigningKey.Wrap(err)
+		}
 		return nil, domain.ErrInvalidToken.Wrap(err)
 	}
 
 	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
+		if err := s.checkRevocation(ctx, claims); err != nil {
+			return nil, err
+		}
 		return claims, nil
 	}
 
 	return nil, domain.ErrInvalidToken
 }
+
+// checkRevocation rejects a token whose jti was explicitly revoked (logout)
+// or whose token_version claim has fallen behind the user's current
+// counter (an admin-forced, session-wide invalidation). A Redis error fails
+// open - same tradeoff as RateLimiter.Allow - so a cache hiccup degrades to
+// "revocation isn't enforced this request" rather than taking auth down.
+func (s *jwtService) checkRevocation(ctx context.Context, claims *JWTClaims) error {
+	revokedCmd := s.redis.Client.B().Exists().Key(s.cacheKeys.RevokedToken(claims.ID)).Build()
+	revoked, err := s.redis.Client.Do(ctx, revokedCmd).ToInt64()
+	if err != nil {
+		s.logger.WarnContext(ctx, "token revocation check failed, failing open", "error", err)
+		return nil
+	}
+	if revoked > 0 {
+		return domain.ErrTokenRevoked
+	}
+
+	versionCmd := s.redis.Client.B().Get().Key(s.cacheKeys.TokenVersion(claims.GetUserIDAsInt())).Build()
+	raw, err := s.redis.Client.Do(ctx, versionCmd).ToString()
+	if err != nil {
+		if rueidis.IsRedisNil(err) {
+			// No revocation has ever been issued for this user.
+			return nil
+		}
+		s.logger.WarnContext(ctx, "token_version check failed, failing open", "error", err)
+		return nil
+	}
+
+	if version, convErr := strconv.Atoi(raw); convErr == nil && claims.TokenVersion < version {
+		return domain.ErrTokenRevoked
+	}
+	return nil
+}
+
+// keyFunc picks the verification key for token based on its header: HS256
+// against the static secret when JWKS isn't configured, or RS256/RS512/
+// ES256/ES384 resolved by kid against the JWKS cache when it is. Only
+// algorithms present in JWT.AllowedAlgorithms are accepted; everything else,
+// including "none", is rejected with errUnsupportedSigningMethod.
+func (s *jwtService) keyFunc(token *jwt.Token) (interface{}, error) {
+	alg := token.Method.Alg()
+	if !s.allowedAlg[alg] {
+		return nil, fmt.Errorf("%w: %s", errUnsupportedSigningMethod, alg)
+	}
+
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		if s.jwks != nil {
+			return nil, fmt.Errorf("%w: HMAC tokens not accepted while JWKS is configured", errUnsupportedSigningMethod)
+		}
+		return []byte(s.config.JWT.Secret), nil
+
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+		if s.jwks == nil {
+			return nil, fmt.Errorf("%w: no JWKSURL configured for asymmetric tokens", errUnsupportedSigningMethod)
+		}
+
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			return nil, fmt.Errorf("%w: token is missing a kid header", errUnknownSigningKey)
+		}
+
+		key, ok := s.jwks.Key(context.Background(), kid)
+		if !ok {
+			return nil, fmt.Errorf("%w: kid %q", errUnknownSigningKey, kid)
+		}
+		return key, nil
+
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnsupportedSigningMethod, alg)
+	}
+}
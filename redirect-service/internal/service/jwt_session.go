@@ -0,0 +1,398 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/hoggir/re-path/redirect-service/internal/domain"
+	"github.com/redis/rueidis"
+)
+
+// TokenPair is what a successful login, refresh, or rotation hands back to
+// the caller.
+type TokenPair struct {
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	AccessExpiresIn  int64  `json:"expires_in"`
+	RefreshExpiresIn int64  `json:"refresh_expires_in"`
+}
+
+// refreshTokenRecord is the Redis-side state for one outstanding refresh
+// token, keyed by CacheKeyGenerator.RefreshToken(jti) (jti doubling as the
+// token value itself - refresh tokens are opaque, not JWTs).
+type refreshTokenRecord struct {
+	UserID int    `json:"user_id"`
+	Email  string `json:"email"`
+	Role   string `json:"role"`
+	// Successor is set the first time this token is redeemed, so a retry of
+	// the same redemption within JWT.RefreshRotationGrace gets the same
+	// pair back instead of being rejected as reuse of an already-rotated
+	// token.
+	Successor *TokenPair `json:"successor,omitempty"`
+	// Claim holds the claimant ID of whichever RefreshTokenPair call is
+	// currently minting this token's successor, set/cleared by
+	// jwtRefreshClaimScript/jwtRefreshFinishScript. Never observed outside
+	// those scripts - it exists so two concurrent redemptions of the same
+	// token can't both see Successor == nil and each mint an independent
+	// pair.
+	Claim string `json:"claim,omitempty"`
+}
+
+// refreshClaimStatus is what jwtRefreshClaimScript reports back about a
+// redemption attempt.
+type refreshClaimStatus int
+
+const (
+	// refreshClaimMissing means the refresh token doesn't exist (expired,
+	// never issued, or already fully consumed).
+	refreshClaimMissing refreshClaimStatus = iota
+	// refreshClaimRotated means this token was already redeemed and its
+	// successor is cached; the caller should hand that back.
+	refreshClaimRotated
+	// refreshClaimPending means another call is currently minting this
+	// token's successor; the caller should poll until it's rotated.
+	refreshClaimPending
+	// refreshClaimed means the caller now exclusively owns minting this
+	// token's successor, and must call finishRefreshClaim when done.
+	refreshClaimed
+)
+
+// refreshClaimPollInterval is how long a redemption that lost the race waits
+// between polls for the winning call to finish minting the successor.
+const refreshClaimPollInterval = 25 * time.Millisecond
+
+// jwtRefreshClaimScript atomically reads a refresh token record and, if it
+// hasn't already been rotated or claimed by a concurrent redemption, marks
+// it claimed by ARGV[1] and hands the caller the user info needed to mint a
+// successor. This is the same "push the decision into Redis" approach as
+// rateLimitScript/botRateScript: without it, two concurrent redemptions
+// would both GET a record with no successor and each mint an independent
+// pair, defeating rotation/replay-detection.
+var jwtRefreshClaimScript = rueidis.NewLuaScript(`
+local raw = redis.call("GET", KEYS[1])
+if not raw then
+	return nil
+end
+
+local rec = cjson.decode(raw)
+
+if rec.successor then
+	return cjson.encode({status = "rotated", successor = rec.successor})
+end
+
+if rec.claim then
+	return cjson.encode({status = "pending"})
+end
+
+rec.claim = ARGV[1]
+redis.call("SET", KEYS[1], cjson.encode(rec), "KEEPTTL")
+
+return cjson.encode({status = "claimed", user_id = rec.user_id, email = rec.email, role = rec.role})
+`)
+
+// jwtRefreshFinishScript releases a claim taken by jwtRefreshClaimScript.
+// ARGV[2] empty means the mint failed and the claim is released with the
+// record otherwise untouched, so another redemption can retry; non-empty
+// means it succeeded, and the record's successor is set (with TTL ARGV[3], in
+// milliseconds)
+// so the next retry of this same redemption is replayed instead of
+// rejected. The claim-ID check (ARGV[1]) guards against a finish call
+// racing a record that's since expired and been reissued for a new login.
+var jwtRefreshFinishScript = rueidis.NewLuaScript(`
+local raw = redis.call("GET", KEYS[1])
+if not raw then
+	return 0
+end
+
+local rec = cjson.decode(raw)
+if rec.claim ~= ARGV[1] then
+	return 0
+end
+
+rec.claim = nil
+
+if ARGV[2] ~= "" then
+	rec.successor = cjson.decode(ARGV[2])
+	redis.call("SET", KEYS[1], cjson.encode(rec), "PX", ARGV[3])
+else
+	redis.call("SET", KEYS[1], cjson.encode(rec), "KEEPTTL")
+end
+
+return 1
+`)
+
+func (s *jwtService) IssueTokenPair(ctx context.Context, userID int, email, role string) (*TokenPair, error) {
+	if s.jwks != nil {
+		return nil, domain.ErrServiceUnavailable.WithMessage("token issuance is disabled while JWT_JWKS_URL is configured").
+			WithContext("reason", "this service only holds the static HMAC secret, not the JWKS private key")
+	}
+
+	version, err := s.currentTokenVersion(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	access, err := s.signAccessToken(userID, email, role, version)
+	if err != nil {
+		return nil, domain.ErrInternalServer.Wrap(err)
+	}
+
+	refreshToken := uuid.New().String()
+	rec := refreshTokenRecord{UserID: userID, Email: email, Role: role}
+	if err := s.putRefreshRecord(ctx, refreshToken, rec, s.config.JWT.RefreshTokenTTL); err != nil {
+		return nil, err
+	}
+	if err := s.trackRefreshToken(ctx, userID, refreshToken, s.config.JWT.RefreshTokenTTL); err != nil {
+		s.logger.WarnContext(ctx, "failed to track refresh token for user, logout-all won't reach it", "error", err)
+	}
+
+	return &TokenPair{
+		AccessToken:      access,
+		RefreshToken:     refreshToken,
+		AccessExpiresIn:  int64(s.config.JWT.Expiration.Seconds()),
+		RefreshExpiresIn: int64(s.config.JWT.RefreshTokenTTL.Seconds()),
+	}, nil
+}
+
+// RefreshTokenPair redeems refreshToken, rotating it for a new pair.
+// Concurrent redemptions of the same token race on jwtRefreshClaimScript:
+// exactly one wins the claim and mints the successor; the rest poll until
+// it's committed and are handed the same pair, rather than each minting
+// (and being able to replay) their own.
+func (s *jwtService) RefreshTokenPair(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	key := s.cacheKeys.RefreshToken(refreshToken)
+	claimID := uuid.New().String()
+	deadline := time.Now().Add(s.config.JWT.RefreshRotationGrace)
+
+	for {
+		status, rec, err := s.claimRefreshToken(ctx, key, claimID)
+		if err != nil {
+			return nil, err
+		}
+
+		switch status {
+		case refreshClaimMissing:
+			return nil, domain.ErrRefreshTokenInvalid
+
+		case refreshClaimRotated:
+			return rec.Successor, nil
+
+		case refreshClaimPending:
+			if time.Now().After(deadline) {
+				return nil, domain.ErrRefreshTokenInvalid.
+					WithMessage("timed out waiting for a concurrent refresh of this token to finish")
+			}
+			select {
+			case <-time.After(refreshClaimPollInterval):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			continue
+
+		case refreshClaimed:
+			pair, err := s.IssueTokenPair(ctx, rec.UserID, rec.Email, rec.Role)
+			if err != nil {
+				if releaseErr := s.finishRefreshClaim(ctx, key, claimID, nil, 0); releaseErr != nil {
+					s.logger.WarnContext(ctx, "failed to release refresh token claim after mint failure", "error", releaseErr)
+				}
+				return nil, err
+			}
+
+			if err := s.finishRefreshClaim(ctx, key, claimID, pair, s.config.JWT.RefreshRotationGrace); err != nil {
+				s.logger.WarnContext(ctx, "failed to record refresh token successor, a retried request will be rejected instead of replayed", "error", err)
+			}
+			if err := s.untrackRefreshToken(ctx, rec.UserID, refreshToken); err != nil {
+				s.logger.WarnContext(ctx, "failed to untrack rotated refresh token", "error", err)
+			}
+
+			return pair, nil
+		}
+	}
+}
+
+// claimRefreshTokenResult is jwtRefreshClaimScript's JSON reply shape.
+type claimRefreshTokenResult struct {
+	Status    string     `json:"status"`
+	Successor *TokenPair `json:"successor,omitempty"`
+	UserID    int        `json:"user_id,omitempty"`
+	Email     string     `json:"email,omitempty"`
+	Role      string     `json:"role,omitempty"`
+}
+
+func (s *jwtService) claimRefreshToken(ctx context.Context, key, claimID string) (refreshClaimStatus, *refreshTokenRecord, error) {
+	resp := jwtRefreshClaimScript.Exec(ctx, s.redis.Client, []string{key}, []string{claimID})
+	if resp.Error() != nil {
+		if rueidis.IsRedisNil(resp.Error()) {
+			return refreshClaimMissing, nil, nil
+		}
+		return 0, nil, domain.ErrCacheError.
+			WithContext("operation", "RefreshTokenPair").
+			Wrap(resp.Error())
+	}
+
+	raw, err := resp.ToString()
+	if err != nil {
+		return 0, nil, domain.ErrCacheError.
+			WithContext("operation", "RefreshTokenPair").
+			Wrap(err)
+	}
+
+	var result claimRefreshTokenResult
+	if err := json.Unmarshal([]byte(raw), &result); err != nil {
+		return 0, nil, domain.ErrRefreshTokenInvalid.Wrap(err)
+	}
+
+	switch result.Status {
+	case "rotated":
+		return refreshClaimRotated, &refreshTokenRecord{Successor: result.Successor}, nil
+	case "pending":
+		return refreshClaimPending, nil, nil
+	case "claimed":
+		return refreshClaimed, &refreshTokenRecord{UserID: result.UserID, Email: result.Email, Role: result.Role}, nil
+	default:
+		return 0, nil, domain.ErrRefreshTokenInvalid
+	}
+}
+
+// finishRefreshClaim releases the claim taken by claimRefreshToken. A nil
+// successor releases the claim without rotating (the mint failed); a
+// non-nil successor commits it with ttl, so a retried redemption of the
+// same token within that window is replayed instead of rejected.
+func (s *jwtService) finishRefreshClaim(ctx context.Context, key, claimID string, successor *TokenPair, ttl time.Duration) error {
+	var successorJSON string
+	if successor != nil {
+		data, err := json.Marshal(successor)
+		if err != nil {
+			return domain.ErrCacheError.
+				WithContext("operation", "RefreshTokenPair").
+				Wrap(err)
+		}
+		successorJSON = string(data)
+	}
+
+	resp := jwtRefreshFinishScript.Exec(ctx, s.redis.Client, []string{key}, []string{
+		claimID,
+		successorJSON,
+		strconv.FormatInt(ttl.Milliseconds(), 10),
+	})
+	return resp.Error()
+}
+
+func (s *jwtService) Logout(ctx context.Context, userID int, accessJTI string) error {
+	if accessJTI != "" {
+		revokeCmd := s.redis.Client.B().Set().Key(s.cacheKeys.RevokedToken(accessJTI)).Value("1").Ex(s.config.JWT.Expiration).Build()
+		if err := s.redis.Client.Do(ctx, revokeCmd).Error(); err != nil {
+			return domain.ErrCacheError.
+				WithContext("operation", "Logout").
+				Wrap(err)
+		}
+	}
+
+	versionCmd := s.redis.Client.B().Incr().Key(s.cacheKeys.TokenVersion(userID)).Build()
+	if err := s.redis.Client.Do(ctx, versionCmd).Error(); err != nil {
+		return domain.ErrCacheError.
+			WithContext("operation", "Logout").
+			Wrap(err)
+	}
+
+	setKey := s.cacheKeys.UserRefreshTokens(userID)
+	membersCmd := s.redis.Client.B().Smembers().Key(setKey).Build()
+	jtis, err := s.redis.Client.Do(ctx, membersCmd).AsStrSlice()
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to list outstanding refresh tokens on logout, they'll stay redeemable until they expire", "userID", userID, "error", err)
+		return nil
+	}
+
+	for _, jti := range jtis {
+		delCmd := s.redis.Client.B().Del().Key(s.cacheKeys.RefreshToken(jti)).Build()
+		if err := s.redis.Client.Do(ctx, delCmd).Error(); err != nil {
+			s.logger.WarnContext(ctx, "failed to revoke refresh token on logout", "userID", userID, "error", err)
+		}
+	}
+
+	delSetCmd := s.redis.Client.B().Del().Key(setKey).Build()
+	if err := s.redis.Client.Do(ctx, delSetCmd).Error(); err != nil {
+		s.logger.WarnContext(ctx, "failed to clear outstanding refresh token set on logout", "userID", userID, "error", err)
+	}
+
+	return nil
+}
+
+func (s *jwtService) signAccessToken(userID int, email, role string, tokenVersion int) (string, error) {
+	now := time.Now()
+	claims := &JWTClaims{
+		Sub:          userID,
+		Email:        email,
+		Role:         role,
+		TokenVersion: tokenVersion,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Issuer:    s.config.JWT.Issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.config.JWT.Expiration)),
+		},
+	}
+	if s.config.JWT.Audience != "" {
+		claims.RegisteredClaims.Audience = jwt.ClaimStrings{s.config.JWT.Audience}
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(s.config.JWT.Secret))
+}
+
+func (s *jwtService) currentTokenVersion(ctx context.Context, userID int) (int, error) {
+	cmd := s.redis.Client.B().Get().Key(s.cacheKeys.TokenVersion(userID)).Build()
+	raw, err := s.redis.Client.Do(ctx, cmd).ToString()
+	if err != nil {
+		if rueidis.IsRedisNil(err) {
+			return 0, nil
+		}
+		return 0, domain.ErrCacheError.
+			WithContext("operation", "currentTokenVersion").
+			Wrap(err)
+	}
+
+	version, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, nil
+	}
+	return version, nil
+}
+
+func (s *jwtService) putRefreshRecord(ctx context.Context, refreshToken string, rec refreshTokenRecord, ttl time.Duration) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return domain.ErrCacheError.
+			WithContext("operation", "putRefreshRecord").
+			Wrap(err)
+	}
+
+	cmd := s.redis.Client.B().Set().Key(s.cacheKeys.RefreshToken(refreshToken)).Value(rueidis.BinaryString(data)).Ex(ttl).Build()
+	if err := s.redis.Client.Do(ctx, cmd).Error(); err != nil {
+		return domain.ErrCacheError.
+			WithContext("operation", "putRefreshRecord").
+			Wrap(err)
+	}
+	return nil
+}
+
+func (s *jwtService) trackRefreshToken(ctx context.Context, userID int, refreshToken string, ttl time.Duration) error {
+	key := s.cacheKeys.UserRefreshTokens(userID)
+	addCmd := s.redis.Client.B().Sadd().Key(key).Member(refreshToken).Build()
+	if err := s.redis.Client.Do(ctx, addCmd).Error(); err != nil {
+		return domain.ErrCacheError.
+			WithContext("operation", "trackRefreshToken").
+			Wrap(err)
+	}
+
+	expireCmd := s.redis.Client.B().Expire().Key(key).Seconds(int64(ttl.Seconds())).Build()
+	return s.redis.Client.Do(ctx, expireCmd).Error()
+}
+
+func (s *jwtService) untrackRefreshToken(ctx context.Context, userID int, refreshToken string) error {
+	cmd := s.redis.Client.B().Srem().Key(s.cacheKeys.UserRefreshTokens(userID)).Member(refreshToken).Build()
+	return s.redis.Client.Do(ctx, cmd).Error()
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/hoggir/re-path/redirect-service/internal/config"
+	"github.com/hoggir/re-path/redirect-service/internal/database"
+	"github.com/redis/rueidis"
+)
+
+// newTestJWTService spins up an in-memory Redis (miniredis) and wires a real
+// jwtService against it, so RefreshTokenPair's Lua scripts run against
+// actual Redis semantics rather than a hand-rolled fake.
+func newTestJWTService(t *testing.T) *jwtService {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+
+	client, err := rueidis.NewClient(rueidis.ClientOption{
+		InitAddress:  []string{mr.Addr()},
+		DisableCache: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to connect to miniredis: %v", err)
+	}
+	t.Cleanup(client.Close)
+
+	cfg := &config.Config{
+		App: config.AppConfig{Name: "repath"},
+		JWT: config.JWTConfig{
+			Secret:               "test-secret",
+			Expiration:           time.Minute,
+			RefreshTokenTTL:      time.Hour,
+			RefreshRotationGrace: 200 * time.Millisecond,
+			Issuer:               "re-path-redirect-service",
+			AllowedAlgorithms:    "HS256",
+		},
+	}
+
+	return &jwtService{
+		config:     cfg,
+		logger:     testLogger(),
+		allowedAlg: parseAllowedAlgorithms(cfg.JWT.AllowedAlgorithms),
+		redis:      &database.Redis{Client: client},
+		cacheKeys:  NewCacheKeyGenerator(cfg),
+	}
+}
+
+// TestRefreshTokenPair_ConcurrentRedemptionsShareOneSuccessor fires many
+// concurrent RefreshTokenPair calls for the same refresh token and asserts
+// they all converge on the same successor pair instead of each minting
+// (and being able to replay) an independent one.
+func TestRefreshTokenPair_ConcurrentRedemptionsShareOneSuccessor(t *testing.T) {
+	s := newTestJWTService(t)
+	ctx := context.Background()
+
+	initial, err := s.IssueTokenPair(ctx, 42, "user@example.com", "admin")
+	if err != nil {
+		t.Fatalf("IssueTokenPair() error = %v", err)
+	}
+
+	const concurrency = 20
+	var wg sync.WaitGroup
+	results := make([]*TokenPair, concurrency)
+	errs := make([]error, concurrency)
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = s.RefreshTokenPair(ctx, initial.RefreshToken)
+		}(i)
+	}
+	wg.Wait()
+
+	var firstSuccessor string
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("RefreshTokenPair() call %d error = %v", i, err)
+		}
+		if firstSuccessor == "" {
+			firstSuccessor = results[i].RefreshToken
+		}
+		if results[i].RefreshToken != firstSuccessor {
+			t.Fatalf("RefreshTokenPair() call %d returned a different successor than call 0: %q vs %q - concurrent redemptions minted independent pairs", i, results[i].RefreshToken, firstSuccessor)
+		}
+	}
+}
+
+// TestRefreshTokenPair_RetryWithinGraceReplaysSuccessor exercises the
+// already-rotated path once a successor has been committed.
+func TestRefreshTokenPair_RetryWithinGraceReplaysSuccessor(t *testing.T) {
+	s := newTestJWTService(t)
+	ctx := context.Background()
+
+	initial, err := s.IssueTokenPair(ctx, 7, "user@example.com", "member")
+	if err != nil {
+		t.Fatalf("IssueTokenPair() error = %v", err)
+	}
+
+	first, err := s.RefreshTokenPair(ctx, initial.RefreshToken)
+	if err != nil {
+		t.Fatalf("RefreshTokenPair() first call error = %v", err)
+	}
+
+	second, err := s.RefreshTokenPair(ctx, initial.RefreshToken)
+	if err != nil {
+		t.Fatalf("RefreshTokenPair() replay error = %v", err)
+	}
+
+	if second.RefreshToken != first.RefreshToken {
+		t.Fatalf("RefreshTokenPair() replay returned a different pair: %q vs %q", second.RefreshToken, first.RefreshToken)
+	}
+}
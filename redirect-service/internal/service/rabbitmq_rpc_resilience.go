@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/hoggir/re-path/redirect-service/internal/config"
+	"github.com/hoggir/re-path/redirect-service/internal/domain"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/sony/gobreaker"
+)
+
+var (
+	rpcCallsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "repath_rpc_calls_total",
+		Help: "Total RabbitMQ RPC calls, partitioned by queue and outcome.",
+	}, []string{"queue", "outcome"})
+
+	rpcDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "repath_rpc_duration_seconds",
+		Help:    "RabbitMQ RPC call duration by queue, covering a single attempt.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"queue"})
+)
+
+// CallOptions controls the resilience behavior CallWithOptions layers on top
+// of a single RPC attempt.
+type CallOptions struct {
+	// Idempotent must be true for MaxAttempts to have any effect; a call
+	// with side effects on the remote that aren't safe to repeat is always
+	// sent at most once, regardless of MaxAttempts.
+	Idempotent bool
+	// MaxAttempts caps retries for an Idempotent call; 0 or 1 means no retry.
+	MaxAttempts int
+	// Headers are sent as AMQP message headers alongside the request, e.g.
+	// TypedCall's contract_version for schema negotiation on the Python side.
+	Headers amqp.Table
+}
+
+// DefaultCallOptions is what Call uses: a single, non-retried attempt,
+// preserving the RPC's original at-most-once semantics.
+var DefaultCallOptions = CallOptions{Idempotent: false, MaxAttempts: 1}
+
+// bulkhead is a counting semaphore limiting concurrent in-flight calls
+// against a single queue, so a slow remote can't turn unbounded caller
+// goroutines into unbounded goroutines stuck waiting on it.
+type bulkhead struct {
+	slots chan struct{}
+}
+
+func newBulkhead(max int) *bulkhead {
+	if max <= 0 {
+		max = 1
+	}
+	return &bulkhead{slots: make(chan struct{}, max)}
+}
+
+func (b *bulkhead) TryAcquire() bool {
+	select {
+	case b.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (b *bulkhead) Release() {
+	<-b.slots
+}
+
+// queueResilience bundles the circuit breaker and bulkhead guarding a single
+// queue; rabbitMQRPCService keeps one per queue name, created lazily on
+// first use since queue names aren't known until the first Call.
+type queueResilience struct {
+	breaker  *gobreaker.CircuitBreaker
+	bulkhead *bulkhead
+}
+
+func newQueueResilience(queueName string, cfg *config.Config) *queueResilience {
+	settings := gobreaker.Settings{
+		Name:        queueName,
+		MaxRequests: 1, // allow one half-open probe before fully closing again
+		Timeout:     cfg.RabbitMQ.RPCBreakerCooldown,
+		ReadyToTrip: func(counts gobreaker.Counts) bool {
+			if counts.Requests < cfg.RabbitMQ.RPCBreakerMinRequests {
+				return false
+			}
+			return float64(counts.TotalFailures)/float64(counts.Requests) >= cfg.RabbitMQ.RPCBreakerFailureRatio
+		},
+	}
+
+	return &queueResilience{
+		breaker:  gobreaker.NewCircuitBreaker(settings),
+		bulkhead: newBulkhead(cfg.RabbitMQ.RPCBulkheadMaxConcurrent),
+	}
+}
+
+func (s *rabbitMQRPCService) queueResilience(queueName string) *queueResilience {
+	s.resilienceMu.Lock()
+	defer s.resilienceMu.Unlock()
+
+	qr, ok := s.resilience[queueName]
+	if !ok {
+		qr = newQueueResilience(queueName, s.cfg)
+		s.resilience[queueName] = qr
+	}
+	return qr
+}
+
+func (s *rabbitMQRPCService) Call(ctx context.Context, queueName string, payload interface{}, timeout time.Duration) ([]byte, error) {
+	return s.CallWithOptions(ctx, queueName, payload, timeout, DefaultCallOptions)
+}
+
+func (s *rabbitMQRPCService) CallWithOptions(ctx context.Context, queueName string, payload interface{}, timeout time.Duration, opts CallOptions) ([]byte, error) {
+	qr := s.queueResilience(queueName)
+
+	maxAttempts := 1
+	if opts.Idempotent && opts.MaxAttempts > maxAttempts {
+		maxAttempts = opts.MaxAttempts
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if attempt > 0 {
+			if err := sleepBackoff(ctx, attempt, s.cfg.RabbitMQ.RPCRetryBaseDelay, s.cfg.RabbitMQ.RPCRetryMaxDelay); err != nil {
+				return nil, domain.ErrTimeout.WithContext("queue", queueName).Wrap(err)
+			}
+		}
+
+		if !qr.bulkhead.TryAcquire() {
+			rpcCallsTotal.WithLabelValues(queueName, "bulkhead_rejected").Inc()
+			return nil, domain.ErrRateLimitExceeded.
+				WithContext("queue", queueName).
+				WithMessage("too many concurrent RPC calls in flight for this queue")
+		}
+
+		start := time.Now()
+		result, err := qr.breaker.Execute(func() (interface{}, error) {
+			return s.doCall(ctx, queueName, payload, timeout, opts.Headers)
+		})
+		qr.bulkhead.Release()
+		rpcDuration.WithLabelValues(queueName).Observe(time.Since(start).Seconds())
+
+		if err == nil {
+			rpcCallsTotal.WithLabelValues(queueName, "success").Inc()
+			return result.([]byte), nil
+		}
+
+		if errors.Is(err, gobreaker.ErrOpenState) || errors.Is(err, gobreaker.ErrTooManyRequests) {
+			rpcCallsTotal.WithLabelValues(queueName, "breaker_open").Inc()
+			return nil, domain.ErrServiceUnavailable.
+				WithContext("queue", queueName).
+				WithMessage("RPC transport is temporarily unavailable").
+				Wrap(err)
+		}
+
+		rpcCallsTotal.WithLabelValues(queueName, "failure").Inc()
+		lastErr = err
+	}
+
+	return nil, lastErr
+}
+
+// sleepBackoff waits a capped-exponential, fully-jittered delay before the
+// given retry attempt (attempt 1 is the first retry), returning early with
+// ctx.Err() if ctx finishes first.
+func sleepBackoff(ctx context.Context, attempt int, base, max time.Duration) error {
+	exp := float64(base) * math.Pow(2, float64(attempt-1))
+	if exp > float64(max) {
+		exp = float64(max)
+	}
+	delay := time.Duration(rand.Int63n(int64(exp) + 1))
+
+	select {
+	case <-time.After(delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hoggir/re-path/redirect-service/internal/domain"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestRabbitMQRPCService_CircuitBreakerOpensAfterFailures(t *testing.T) {
+	var publishes int32
+	channel := newFakeChannel()
+
+	cfg := testRPCConfig()
+	cfg.RabbitMQ.RPCBreakerMinRequests = 2
+	cfg.RabbitMQ.RPCBreakerFailureRatio = 0.5
+
+	svc := newRabbitMQRPCService(channel, cfg, testLogger())
+	go svc.consume()
+
+	channel.onPublish = func(_ amqp.Publishing) error {
+		atomic.AddInt32(&publishes, 1)
+		return errors.New("broker unreachable")
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := svc.Call(context.Background(), "queue", "payload", 20*time.Millisecond); err == nil {
+			t.Fatalf("call %d: expected an error from the fake broker", i)
+		}
+	}
+
+	_, err := svc.Call(context.Background(), "queue", "payload", 20*time.Millisecond)
+	var appErr *domain.AppError
+	if !errors.As(err, &appErr) || appErr.Code != domain.ErrServiceUnavailable.Code {
+		t.Fatalf("expected ErrServiceUnavailable once breaker trips, got %v", err)
+	}
+
+	if got := atomic.LoadInt32(&publishes); got != 2 {
+		t.Errorf("breaker-open call should fail fast without publishing; got %d publishes, want 2", got)
+	}
+}
+
+func TestRabbitMQRPCService_BulkheadRejectsOverCapacity(t *testing.T) {
+	channel := newFakeChannel() // never replies, so in-flight calls stay in-flight
+
+	cfg := testRPCConfig()
+	cfg.RabbitMQ.RPCBulkheadMaxConcurrent = 1
+
+	svc := newRabbitMQRPCService(channel, cfg, testLogger())
+	go svc.consume()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		svc.Call(context.Background(), "queue", "payload", 200*time.Millisecond)
+	}()
+
+	// Give the first call time to acquire the bulkhead's only slot.
+	time.Sleep(20 * time.Millisecond)
+
+	_, err := svc.Call(context.Background(), "queue", "payload", 200*time.Millisecond)
+	var appErr *domain.AppError
+	if !errors.As(err, &appErr) || appErr.Code != domain.ErrRateLimitExceeded.Code {
+		t.Fatalf("expected ErrRateLimitExceeded, got %v", err)
+	}
+
+	wg.Wait()
+}
+
+func TestRabbitMQRPCService_CallWithOptionsRetriesIdempotentCalls(t *testing.T) {
+	var attempts int32
+	channel := newFakeChannel()
+	channel.onPublish = func(msg amqp.Publishing) error {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			return errors.New("transient broker error")
+		}
+		go func() {
+			channel.deliveries <- amqp.Delivery{CorrelationId: msg.CorrelationId, Body: []byte("ok")}
+		}()
+		return nil
+	}
+
+	svc := newRabbitMQRPCService(channel, testRPCConfig(), testLogger())
+	go svc.consume()
+
+	body, err := svc.CallWithOptions(context.Background(), "queue", "payload", time.Second, CallOptions{Idempotent: true, MaxAttempts: 5})
+	if err != nil {
+		t.Fatalf("expected the retried call to eventually succeed, got %v", err)
+	}
+	if string(body) != "ok" {
+		t.Errorf("got body %q, want %q", body, "ok")
+	}
+	if got := atomic.LoadInt32(&attempts); got != 3 {
+		t.Errorf("expected 3 publish attempts, got %d", got)
+	}
+}
+
+func TestRabbitMQRPCService_CallDoesNotRetryByDefault(t *testing.T) {
+	var attempts int32
+	channel := newFakeChannel()
+	channel.onPublish = func(_ amqp.Publishing) error {
+		atomic.AddInt32(&attempts, 1)
+		return errors.New("broker unreachable")
+	}
+
+	svc := newRabbitMQRPCService(channel, testRPCConfig(), testLogger())
+	go svc.consume()
+
+	if _, err := svc.Call(context.Background(), "queue", "payload", 20*time.Millisecond); err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+	if got := atomic.LoadInt32(&attempts); got != 1 {
+		t.Errorf("Call must not retry; expected 1 publish attempt, got %d", got)
+	}
+}
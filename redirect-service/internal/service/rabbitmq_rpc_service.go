@@ -3,70 +3,186 @@ package service
 import (
 	"context"
 	"encoding/json"
-	"fmt"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/hoggir/re-path/redirect-service/internal/config"
 	"github.com/hoggir/re-path/redirect-service/internal/database"
+	"github.com/hoggir/re-path/redirect-service/internal/domain"
 	"github.com/hoggir/re-path/redirect-service/internal/logger"
+	"github.com/hoggir/re-path/redirect-service/internal/reqctx"
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
+// replyToQueue is RabbitMQ's direct reply-to pseudo-queue: publishing with
+// this as ReplyTo skips declaring a real queue per call and routes the reply
+// straight back over the channel that sent the request.
+const replyToQueue = "amq.rabbitmq.reply-to"
+
 type RabbitMQRPCService interface {
+	// Call is CallWithOptions with DefaultCallOptions: a single, non-retried
+	// attempt, preserving the RPC's original at-most-once semantics.
 	Call(ctx context.Context, queueName string, payload interface{}, timeout time.Duration) ([]byte, error)
+	// CallWithOptions wraps a single RPC attempt with a per-queue circuit breaker, a
+	// bulkhead limiting concurrent in-flight calls, and - for Idempotent
+	// options - retries with full-jitter exponential backoff. See
+	// CallOptions.
+	CallWithOptions(ctx context.Context, queueName string, payload interface{}, timeout time.Duration, opts CallOptions) ([]byte, error)
+}
+
+// amqpChannel is the subset of *amqp.Channel this service calls, narrowed to
+// an interface so tests can exercise the demultiplexer against a fake
+// broker instead of a live RabbitMQ connection.
+type amqpChannel interface {
+	Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error)
+	PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
 }
 
+// rabbitMQRPCService multiplexes every outstanding RPC call over a single
+// direct reply-to consumer instead of declaring a fresh exclusive queue per
+// call. pending tracks the channel waiting for each correlation ID; the
+// consumer goroutine started by NewRabbitMQRPCService demultiplexes
+// deliveries onto it by CorrelationId.
 type rabbitMQRPCService struct {
-	rabbitmq *database.RabbitMQ
-	logger   logger.Logger
+	// channelProvider is called fresh for every Consume/PublishWithContext
+	// rather than read once, so a database.RabbitMQ reconnect is picked up
+	// transparently instead of pinning this service to a channel that's
+	// since been closed.
+	channelProvider func() amqpChannel
+	cfg             *config.Config
+	logger          logger.Logger
+
+	mu      sync.Mutex
+	pending map[string]chan amqp.Delivery
+
+	resilienceMu sync.Mutex
+	resilience   map[string]*queueResilience
+}
+
+func NewRabbitMQRPCService(rabbitmq *database.RabbitMQ, cfg *config.Config, log logger.Logger) RabbitMQRPCService {
+	s := newRabbitMQRPCServiceWithProvider(func() amqpChannel { return rabbitmq.Channel() }, cfg, log)
+	go s.consume()
+	return s
+}
+
+// newRabbitMQRPCService is the test-facing constructor: it pins
+// channelProvider to a single, already-open channel, matching what the
+// existing fake-channel test doubles expect.
+func newRabbitMQRPCService(channel amqpChannel, cfg *config.Config, log logger.Logger) *rabbitMQRPCService {
+	return newRabbitMQRPCServiceWithProvider(func() amqpChannel { return channel }, cfg, log)
 }
 
-func NewRabbitMQRPCService(rabbitmq *database.RabbitMQ, log logger.Logger) RabbitMQRPCService {
+func newRabbitMQRPCServiceWithProvider(channelProvider func() amqpChannel, cfg *config.Config, log logger.Logger) *rabbitMQRPCService {
 	return &rabbitMQRPCService{
-		rabbitmq: rabbitmq,
-		logger:   log,
+		channelProvider: channelProvider,
+		cfg:             cfg,
+		logger:          log,
+		pending:         make(map[string]chan amqp.Delivery),
+		resilience:      make(map[string]*queueResilience),
 	}
 }
 
-func (s *rabbitMQRPCService) Call(ctx context.Context, queueName string, payload interface{}, timeout time.Duration) ([]byte, error) {
-	// Serialize payload to JSON
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+// consume runs for the lifetime of the service, (re)subscribing to the
+// direct reply-to pseudo-queue and dispatching each delivery to the channel
+// registered under its CorrelationId. If the channel/connection drops, every
+// in-flight call is failed and the consumer is re-established once the
+// channel recovers.
+func (s *rabbitMQRPCService) consume() {
+	for {
+		msgs, err := s.channelProvider().Consume(
+			replyToQueue,
+			"",    // consumer (auto-generated)
+			true,  // auto-ack: direct reply-to requires no-ack
+			false, // exclusive
+			false, // no-local
+			false, // no-wait
+			nil,   // args
+		)
+		if err != nil {
+			s.logger.Error("failed to start RPC reply consumer, retrying", "error", err)
+			s.failAllPending()
+			time.Sleep(time.Second)
+			continue
+		}
+
+		for msg := range msgs {
+			s.dispatch(msg)
+		}
+
+		// msgs closed: channel or connection was lost underneath us.
+		s.logger.Warn("RPC reply consumer channel closed, failing in-flight calls and reconnecting")
+		s.failAllPending()
 	}
+}
 
-	// Declare a temporary exclusive queue for receiving response
-	replyQueue, err := s.rabbitmq.Channel.QueueDeclare(
-		"",    // name (empty = auto-generated)
-		false, // durable
-		true,  // delete when unused
-		true,  // exclusive
-		false, // no-wait
-		nil,   // arguments
-	)
+func (s *rabbitMQRPCService) dispatch(msg amqp.Delivery) {
+	s.mu.Lock()
+	ch, ok := s.pending[msg.CorrelationId]
+	if ok {
+		delete(s.pending, msg.CorrelationId)
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		s.logger.Warn("received RPC reply with no waiting caller", "correlationId", msg.CorrelationId)
+		return
+	}
+
+	ch <- msg
+}
+
+func (s *rabbitMQRPCService) failAllPending() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for correlationID, ch := range s.pending {
+		close(ch)
+		delete(s.pending, correlationID)
+	}
+}
+
+func (s *rabbitMQRPCService) register(correlationID string) chan amqp.Delivery {
+	ch := make(chan amqp.Delivery, 1)
+	s.mu.Lock()
+	s.pending[correlationID] = ch
+	s.mu.Unlock()
+	return ch
+}
+
+func (s *rabbitMQRPCService) unregister(correlationID string) {
+	s.mu.Lock()
+	delete(s.pending, correlationID)
+	s.mu.Unlock()
+}
+
+// doCall is a single RPC attempt: publish, then wait up to timeout for a
+// reply. CallWithOptions is what adds the breaker/bulkhead/retry layer
+// described on RabbitMQRPCService; doCall has no knowledge of any of that.
+// headers, if non-nil, are sent as AMQP message headers (e.g. TypedCall's
+// contract_version). If ctx carries a request ID (see reqctx), it's added
+// to headers as "request_id" so analytic-service can continue this
+// request's correlation ID in its own logs.
+func (s *rabbitMQRPCService) doCall(ctx context.Context, queueName string, payload interface{}, timeout time.Duration, headers amqp.Table) ([]byte, error) {
+	body, err := json.Marshal(payload)
 	if err != nil {
-		return nil, fmt.Errorf("failed to declare reply queue: %w", err)
+		return nil, domain.ErrQueueError.
+			WithContext("queue", queueName).
+			WithContext("operation", "Marshal").
+			Wrap(err)
 	}
 
-	// Generate unique correlation ID for this request
 	correlationID := uuid.New().String()
+	replyCh := s.register(correlationID)
 
-	// Register consumer for reply queue
-	msgs, err := s.rabbitmq.Channel.Consume(
-		replyQueue.Name, // queue
-		"",              // consumer (empty = auto-generated)
-		true,            // auto-ack
-		false,           // exclusive
-		false,           // no-local
-		false,           // no-wait
-		nil,             // args
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to register consumer: %w", err)
+	if requestID := reqctx.RequestID(ctx); requestID != "" {
+		if headers == nil {
+			headers = amqp.Table{}
+		}
+		headers["request_id"] = requestID
 	}
 
-	// Publish request message
-	err = s.rabbitmq.Channel.PublishWithContext(
+	err = s.channelProvider().PublishWithContext(
 		ctx,
 		"",        // exchange
 		queueName, // routing key
@@ -75,30 +191,45 @@ func (s *rabbitMQRPCService) Call(ctx context.Context, queueName string, payload
 		amqp.Publishing{
 			ContentType:   "application/json",
 			CorrelationId: correlationID,
-			ReplyTo:       replyQueue.Name,
+			ReplyTo:       replyToQueue,
 			Body:          body,
 			DeliveryMode:  amqp.Transient,
 			Timestamp:     time.Now(),
+			Headers:       headers,
 		},
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to publish RPC request: %w", err)
+		s.unregister(correlationID)
+		return nil, domain.ErrQueueError.
+			WithContext("queue", queueName).
+			WithContext("operation", "Publish").
+			Wrap(err)
 	}
 
 	s.logger.DebugContext(ctx, "RPC request sent", "queue", queueName, "correlationId", correlationID)
 
 	select {
-	case msg := <-msgs:
-		if msg.CorrelationId == correlationID {
-			// log.Printf("ðŸ“¦ Response body: %s", string(msg.Body))
-			return msg.Body, nil
+	case msg, ok := <-replyCh:
+		if !ok {
+			return nil, domain.ErrQueueError.
+				WithContext("queue", queueName).
+				WithContext("correlationId", correlationID).
+				WithMessage("RPC call failed: reply channel lost connection")
 		}
-		return nil, fmt.Errorf("received message with mismatched correlation ID")
+		return msg.Body, nil
 
 	case <-time.After(timeout):
-		return nil, fmt.Errorf("RPC call timeout after %v", timeout)
+		s.unregister(correlationID)
+		return nil, domain.ErrTimeout.
+			WithContext("queue", queueName).
+			WithContext("correlationId", correlationID).
+			WithContext("timeout", timeout)
 
 	case <-ctx.Done():
-		return nil, fmt.Errorf("RPC call cancelled: %w", ctx.Err())
+		s.unregister(correlationID)
+		return nil, domain.ErrTimeout.
+			WithContext("queue", queueName).
+			WithContext("correlationId", correlationID).
+			Wrap(ctx.Err())
 	}
 }
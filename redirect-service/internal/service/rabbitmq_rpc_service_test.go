@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hoggir/re-path/redirect-service/internal/config"
+	"github.com/hoggir/re-path/redirect-service/internal/domain"
+	"github.com/hoggir/re-path/redirect-service/internal/logger"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// fakeChannel is a minimal amqpChannel double: Consume hands back a channel
+// the test controls directly, and PublishWithContext delegates to a
+// per-test hook so each case can decide whether/when to "reply".
+type fakeChannel struct {
+	deliveries chan amqp.Delivery
+	onPublish  func(msg amqp.Publishing) error
+}
+
+func newFakeChannel() *fakeChannel {
+	return &fakeChannel{deliveries: make(chan amqp.Delivery, 16)}
+}
+
+func (f *fakeChannel) Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error) {
+	return f.deliveries, nil
+}
+
+func (f *fakeChannel) PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error {
+	if f.onPublish != nil {
+		return f.onPublish(msg)
+	}
+	return nil
+}
+
+func testLogger() logger.Logger {
+	return logger.NewLogger(&config.Config{App: config.AppConfig{Env: "test"}})
+}
+
+// testRPCConfig returns resilience settings loose enough that the breaker
+// and bulkhead stay out of the way of tests that aren't exercising them
+// directly: a high failure threshold, a generous bulkhead, and short
+// backoff bounds so retry tests run fast.
+func testRPCConfig() *config.Config {
+	return &config.Config{
+		RabbitMQ: config.RabbitMQConfig{
+			RPCBreakerFailureRatio:   0.5,
+			RPCBreakerMinRequests:    1000,
+			RPCBreakerCooldown:       50 * time.Millisecond,
+			RPCBulkheadMaxConcurrent: 100,
+			RPCRetryBaseDelay:        time.Millisecond,
+			RPCRetryMaxDelay:         10 * time.Millisecond,
+		},
+	}
+}
+
+func TestRabbitMQRPCService_ConcurrentCalls(t *testing.T) {
+	channel := newFakeChannel()
+	channel.onPublish = func(msg amqp.Publishing) error {
+		go func() {
+			channel.deliveries <- amqp.Delivery{
+				CorrelationId: msg.CorrelationId,
+				Body:          append([]byte("reply-for-"), msg.Body...),
+			}
+		}()
+		return nil
+	}
+
+	svc := newRabbitMQRPCService(channel, testRPCConfig(), testLogger())
+	go svc.consume()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			payload := fmt.Sprintf("req-%d", i)
+			body, err := svc.Call(context.Background(), "queue", payload, time.Second)
+			if err != nil {
+				t.Errorf("call %d: unexpected error: %v", i, err)
+				return
+			}
+			want := fmt.Sprintf("reply-for-%q", payload)
+			if string(body) != want {
+				t.Errorf("call %d: got %q, want %q", i, body, want)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestRabbitMQRPCService_Timeout(t *testing.T) {
+	channel := newFakeChannel() // never replies
+
+	svc := newRabbitMQRPCService(channel, testRPCConfig(), testLogger())
+	go svc.consume()
+
+	_, err := svc.Call(context.Background(), "queue", "payload", 20*time.Millisecond)
+	if err == nil {
+		t.Fatal("expected timeout error, got nil")
+	}
+
+	var appErr *domain.AppError
+	if !errors.As(err, &appErr) || appErr.Code != domain.ErrTimeout.Code {
+		t.Fatalf("expected ErrTimeout, got %v", err)
+	}
+
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	if len(svc.pending) != 0 {
+		t.Errorf("expected pending map to be cleaned up after timeout, has %d entries", len(svc.pending))
+	}
+}
+
+func TestRabbitMQRPCService_Cancellation(t *testing.T) {
+	channel := newFakeChannel() // never replies
+
+	svc := newRabbitMQRPCService(channel, testRPCConfig(), testLogger())
+	go svc.consume()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	_, err := svc.Call(ctx, "queue", "payload", time.Second)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected wrapped context.Canceled, got %v", err)
+	}
+}
+
+func TestRabbitMQRPCService_CorrelationIDCollision(t *testing.T) {
+	svc := newRabbitMQRPCService(newFakeChannel(), testRPCConfig(), testLogger())
+
+	first := svc.register("dup-id")
+	second := svc.register("dup-id")
+
+	svc.dispatch(amqp.Delivery{CorrelationId: "dup-id", Body: []byte("payload")})
+
+	select {
+	case _, ok := <-first:
+		if ok {
+			t.Error("expected the superseded registration's channel to receive nothing")
+		}
+	default:
+	}
+
+	select {
+	case msg := <-second:
+		if string(msg.Body) != "payload" {
+			t.Errorf("got body %q, want %q", msg.Body, "payload")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("latest registration never received the delivery")
+	}
+}
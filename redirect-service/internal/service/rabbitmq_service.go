@@ -2,31 +2,91 @@ package service
 
 import (
 	"context"
-	"fmt"
-	"log"
+	"sync"
+	"time"
 
+	"github.com/hoggir/re-path/redirect-service/internal/config"
 	"github.com/hoggir/re-path/redirect-service/internal/database"
+	"github.com/hoggir/re-path/redirect-service/internal/domain"
+	"github.com/hoggir/re-path/redirect-service/internal/eventbus"
+	"github.com/hoggir/re-path/redirect-service/internal/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
+var clickEventsPublishedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "repath_click_events_published_total",
+	Help: "Click events published to RabbitMQ, by outcome.",
+}, []string{"outcome"})
+
+// confirmWait bounds how long PublishClickEvent waits for the broker to
+// ack/nack a publish before giving up and reporting a queue error.
+const confirmWait = 5 * time.Second
+
 type RabbitMQService interface {
+	// PublishClickEvent publishes payload onto the click_events queue and
+	// waits for the broker's publisher confirm before returning, so a
+	// caller only treats the event as durably queued once RabbitMQ has
+	// actually accepted it.
 	PublishClickEvent(ctx context.Context, payload []byte) error
 }
 
+// rabbitMQService publishes over RabbitMQ.PublishChannel(), a channel
+// dedicated to confirm-mode publishing (see database.NewRabbitMQ). Confirms
+// arrive on that channel in the same order publishes were sent, so publishMu
+// serializes PublishClickEvent calls to keep each publish paired with the
+// very next confirmation. boundChannel is whichever *amqp.Channel confirms
+// is currently subscribed to; PublishClickEvent re-subscribes whenever
+// PublishChannel() has moved on to a new one after a reconnect.
 type rabbitMQService struct {
 	rabbitmq *database.RabbitMQ
+	logger   logger.Logger
+
+	publishMu    sync.Mutex
+	boundChannel *amqp.Channel
+	confirms     chan amqp.Confirmation
 }
 
-func NewRabbitMQService(rabbitmq *database.RabbitMQ) RabbitMQService {
+// NewRabbitMQService builds the click_events publisher selected by
+// cfg.EventBus.Driver: rabbitMQService (with its publisher-confirm wait)
+// for "rabbitmq", or an eventBusClickPublisher wrapping bus for "kafka" -
+// see eventbus_click_event.go. Both satisfy RabbitMQService.
+func NewRabbitMQService(rabbitmq *database.RabbitMQ, bus eventbus.EventBus, cfg *config.Config, log logger.Logger) RabbitMQService {
+	if eventbus.Driver(cfg.EventBus.Driver) == eventbus.DriverKafka {
+		return newEventBusClickPublisher(bus, cfg, log)
+	}
+
+	ch := rabbitmq.PublishChannel()
 	return &rabbitMQService{
-		rabbitmq: rabbitmq,
+		rabbitmq:     rabbitmq,
+		logger:       log,
+		boundChannel: ch,
+		confirms:     ch.NotifyPublish(make(chan amqp.Confirmation, 1)),
 	}
 }
 
+// rebind re-subscribes confirms if database.RabbitMQ has moved on to a new
+// publish channel since the last call, e.g. after a reconnect. Must be
+// called with publishMu held.
+func (s *rabbitMQService) rebind() *amqp.Channel {
+	ch := s.rabbitmq.PublishChannel()
+	if ch != s.boundChannel {
+		s.boundChannel = ch
+		s.confirms = ch.NotifyPublish(make(chan amqp.Confirmation, 1))
+	}
+	return ch
+}
+
 func (s *rabbitMQService) PublishClickEvent(ctx context.Context, payload []byte) error {
 	queueName := s.rabbitmq.Config.RabbitMQ.Queues.ClickEvents
 
-	err := s.rabbitmq.Channel.PublishWithContext(
+	s.publishMu.Lock()
+	defer s.publishMu.Unlock()
+
+	ch := s.rebind()
+
+	err := ch.PublishWithContext(
 		ctx,
 		"",
 		queueName,
@@ -36,13 +96,29 @@ func (s *rabbitMQService) PublishClickEvent(ctx context.Context, payload []byte)
 			ContentType:  "application/json",
 			Body:         payload,
 			DeliveryMode: amqp.Persistent,
+			Timestamp:    time.Now(),
 		},
 	)
-
 	if err != nil {
-		return fmt.Errorf("failed to publish message: %w", err)
+		clickEventsPublishedTotal.WithLabelValues("error").Inc()
+		return domain.ErrQueueError.WithContext("queue", queueName).WithContext("operation", "Publish").Wrap(err)
+	}
+
+	select {
+	case confirm, ok := <-s.confirms:
+		if !ok || !confirm.Ack {
+			clickEventsPublishedTotal.WithLabelValues("nack").Inc()
+			return domain.ErrQueueError.WithContext("queue", queueName).WithMessage("broker nacked click event publish")
+		}
+	case <-time.After(confirmWait):
+		clickEventsPublishedTotal.WithLabelValues("confirm_timeout").Inc()
+		return domain.ErrQueueError.WithContext("queue", queueName).WithMessage("timed out waiting for publisher confirm")
+	case <-ctx.Done():
+		clickEventsPublishedTotal.WithLabelValues("error").Inc()
+		return ctx.Err()
 	}
 
-	log.Printf("📤 Published click event to queue: %s (size: %d bytes)", queueName, len(payload))
+	clickEventsPublishedTotal.WithLabelValues("ok").Inc()
+	s.logger.DebugContext(ctx, "published click event", "queue", queueName, "bytes", len(payload))
 	return nil
 }
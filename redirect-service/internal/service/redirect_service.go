@@ -7,6 +7,8 @@ import (
 	"github.com/hoggir/re-path/redirect-service/internal/domain"
 	"github.com/hoggir/re-path/redirect-service/internal/logger"
 	"github.com/hoggir/re-path/redirect-service/internal/repository"
+	"github.com/hoggir/re-path/redirect-service/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type RedirectService interface {
@@ -15,57 +17,68 @@ type RedirectService interface {
 }
 
 type redirectService struct {
-	urlRepo      repository.URLRepository
-	cacheService CacheService
-	cacheKeys    *CacheKeyGenerator
-	config       *config.Config
-	logger       logger.Logger
+	urlRepo          repository.URLRepository
+	cacheService     CacheService
+	cacheInvalidator CacheInvalidator
+	cacheKeys        *CacheKeyGenerator
+	clickBatcher     ClickCountBatcher
+	config           *config.Config
+	logger           logger.Logger
 }
 
 func NewRedirectService(
 	urlRepo repository.URLRepository,
 	cacheService CacheService,
+	cacheInvalidator CacheInvalidator,
 	cacheKeys *CacheKeyGenerator,
+	clickBatcher ClickCountBatcher,
 	cfg *config.Config,
 	log logger.Logger,
 ) RedirectService {
 	return &redirectService{
-		urlRepo:      urlRepo,
-		cacheService: cacheService,
-		cacheKeys:    cacheKeys,
-		config:       cfg,
-		logger:       log,
+		urlRepo:          urlRepo,
+		cacheService:     cacheService,
+		cacheInvalidator: cacheInvalidator,
+		cacheKeys:        cacheKeys,
+		clickBatcher:     clickBatcher,
+		config:           cfg,
+		logger:           log,
 	}
 }
 
 func (s *redirectService) GetURL(ctx context.Context, shortCode string) (*domain.FindByShortCode, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "redirect_service.get_url")
+	defer span.End()
+	span.SetAttributes(attribute.String("short_code", shortCode))
+
 	cacheKey := s.cacheKeys.URL(shortCode)
 
-	var url domain.FindByShortCode
-	err := s.cacheService.Get(ctx, cacheKey, &url)
-	if err == nil {
-		dashboardInvalidFlag := s.cacheKeys.DashboardInvalidationFlag(url.UserID)
-		s.logger.DebugContext(ctx, "cache hit for shortCode", "shortCode", shortCode)
-		s.cacheService.RefreshTTL(ctx, cacheKey, s.config.Redis.CacheTTL)
-		s.cacheService.SetInvalidationFlag(ctx, dashboardInvalidFlag, s.config.Redis.InvalidationFlagTTL)
-		return &url, nil
+	if url, ok := s.cacheInvalidator.Local(shortCode); ok {
+		span.SetAttributes(attribute.Bool("cache_hit", true), attribute.Int("user_id", url.UserID))
+		s.logger.DebugContext(ctx, "local cache hit for shortCode", "shortCode", shortCode)
+		return url, nil
 	}
 
-	urlData, err := s.urlRepo.FindByShortCode(ctx, shortCode)
+	// GetOrLoad's L1 + singleflight coalescing replaces the old GetCached/Set
+	// pair here: a viral shortCode now costs one urlRepo.FindByShortCode call
+	// no matter how many requests arrive while it's in flight.
+	var url domain.FindByShortCode
+	loaderCalled := false
+	err := s.cacheService.GetOrLoad(ctx, cacheKey, s.config.Redis.CacheTTL, func(ctx context.Context) (interface{}, error) {
+		loaderCalled = true
+		return s.urlRepo.FindByShortCode(ctx, shortCode)
+	}, &url)
 	if err != nil {
 		return nil, err
 	}
+	span.SetAttributes(attribute.Bool("cache_hit", !loaderCalled), attribute.Int("user_id", url.UserID))
 
-	if err := s.cacheService.Set(ctx, cacheKey, urlData, s.config.Redis.CacheTTL); err != nil {
-		s.logger.WarnContext(ctx, "failed to cache shortCode", "shortCode", shortCode, "error", err)
-	}
-
-	dashboardInvalidFlag := s.cacheKeys.DashboardInvalidationFlag(urlData.UserID)
+	dashboardInvalidFlag := s.cacheKeys.DashboardInvalidationFlag(url.UserID)
 	s.cacheService.SetInvalidationFlag(ctx, dashboardInvalidFlag, s.config.Redis.InvalidationFlagTTL)
 
-	return urlData, nil
+	return &url, nil
 }
 
 func (s *redirectService) IncrementClickCount(ctx context.Context, shortCode string) error {
-	return s.urlRepo.IncrementClickCount(ctx, shortCode)
+	return s.clickBatcher.Enqueue(ctx, shortCode)
 }
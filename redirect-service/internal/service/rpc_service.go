@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/hoggir/re-path/redirect-service/internal/config"
+	"github.com/hoggir/re-path/redirect-service/internal/domain"
+	"github.com/hoggir/re-path/redirect-service/internal/eventbus"
+)
+
+// NewRPCService picks DashboardClient's RPC transport based on
+// cfg.EventBus.Driver: rabbitMQRPCService (with its circuit breaker and
+// bulkhead) for "rabbitmq", or an eventBusRPCService wrapping bus for
+// "kafka". Both satisfy RPCService, so DashboardClient never has to know
+// which one it got.
+func NewRPCService(cfg *config.Config, rabbitMQRPC RabbitMQRPCService, bus eventbus.EventBus) RPCService {
+	if eventbus.Driver(cfg.EventBus.Driver) == eventbus.DriverKafka {
+		return NewEventBusRPCService(bus)
+	}
+	return rabbitMQRPC
+}
+
+// RPCService is the transport-agnostic request/response surface
+// DashboardClient calls through. rabbitMQRPCService satisfies it directly
+// (see rabbitmq_rpc_service.go) and keeps its existing circuit
+// breaker/bulkhead/retry behavior; eventBusRPCService below wraps any
+// eventbus.EventBus - including the Kafka driver - as a thinner
+// implementation, selected by NewRPCService based on
+// config.EventBusConfig.Driver.
+type RPCService interface {
+	Call(ctx context.Context, route string, payload interface{}, timeout time.Duration) ([]byte, error)
+	CallWithOptions(ctx context.Context, route string, payload interface{}, timeout time.Duration, opts CallOptions) ([]byte, error)
+}
+
+// eventBusRPCService implements RPCService on top of an eventbus.EventBus.
+// It doesn't reproduce rabbitMQRPCService's per-queue circuit breaker and
+// bulkhead - those stay RabbitMQ-specific for now - so CallOptions.Idempotent
+// retries are honored but a failing route has no breaker to trip.
+type eventBusRPCService struct {
+	bus eventbus.EventBus
+}
+
+// NewEventBusRPCService wraps bus as an RPCService, so callers that depend
+// on RPCService rather than RabbitMQRPCService directly keep working
+// whichever driver config.EventBusConfig.Driver selects.
+func NewEventBusRPCService(bus eventbus.EventBus) RPCService {
+	return &eventBusRPCService{bus: bus}
+}
+
+func (s *eventBusRPCService) Call(ctx context.Context, route string, payload interface{}, timeout time.Duration) ([]byte, error) {
+	return s.CallWithOptions(ctx, route, payload, timeout, DefaultCallOptions)
+}
+
+// CallWithOptions honors opts.Idempotent/MaxAttempts; opts.Headers is
+// amqp.Table and EventBus.Call has no header parameter, so - unlike
+// rabbitMQRPCService - headers set on the call (e.g. TypedCall's
+// contract_version) aren't forwarded over the Kafka driver yet.
+func (s *eventBusRPCService) CallWithOptions(ctx context.Context, route string, payload interface{}, timeout time.Duration, opts CallOptions) ([]byte, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, domain.ErrQueueError.WithContext("route", route).WithContext("operation", "Marshal").Wrap(err)
+	}
+
+	maxAttempts := 1
+	if opts.Idempotent && opts.MaxAttempts > maxAttempts {
+		maxAttempts = opts.MaxAttempts
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if attempt > 0 {
+			if err := sleepBackoff(ctx, attempt, 50*time.Millisecond, 2*time.Second); err != nil {
+				return nil, domain.ErrTimeout.WithContext("route", route).Wrap(err)
+			}
+		}
+
+		result, err := s.bus.Call(ctx, route, body, timeout)
+		if err == nil {
+			return result, nil
+		}
+		lastErr = err
+	}
+
+	return nil, lastErr
+}
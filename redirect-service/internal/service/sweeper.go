@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/hoggir/re-path/redirect-service/internal/config"
+	"github.com/hoggir/re-path/redirect-service/internal/logger"
+	"github.com/hoggir/re-path/redirect-service/internal/repository"
+)
+
+const sweeperLockKey = "repath:sweeper:lock"
+
+// SweepResult reports how many items a single sweep pass touched.
+type SweepResult struct {
+	Scope   string `json:"scope"`
+	Touched int    `json:"touched"`
+}
+
+// Sweeper periodically walks the urls collection for documents past their
+// expiresAt and the Redis keyspace for cache entries pointing at now-inactive
+// shortcodes, so both stay bounded even for links nobody ever looks up again.
+type Sweeper interface {
+	// Run fires SweepExpired and SweepLapsedCache on Service.SweeperInterval
+	// until ctx is done, acquiring the leader lock on every tick.
+	Run(ctx context.Context)
+	SweepExpired(ctx context.Context) (SweepResult, error)
+	SweepLapsedCache(ctx context.Context) (SweepResult, error)
+}
+
+type sweeper struct {
+	urlRepo          repository.URLRepository
+	cacheService     CacheService
+	cacheKeys        *CacheKeyGenerator
+	cacheInvalidator CacheInvalidator
+	config           *config.Config
+	logger           logger.Logger
+}
+
+func NewSweeper(
+	urlRepo repository.URLRepository,
+	cacheService CacheService,
+	cacheKeys *CacheKeyGenerator,
+	cacheInvalidator CacheInvalidator,
+	cfg *config.Config,
+	log logger.Logger,
+) Sweeper {
+	return &sweeper{
+		urlRepo:          urlRepo,
+		cacheService:     cacheService,
+		cacheKeys:        cacheKeys,
+		cacheInvalidator: cacheInvalidator,
+		config:           cfg,
+		logger:           log,
+	}
+}
+
+func (s *sweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.Service.SweeperInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.tick(ctx)
+		}
+	}
+}
+
+func (s *sweeper) tick(ctx context.Context) {
+	acquired, err := s.cacheService.SetNX(ctx, sweeperLockKey, "1", s.config.Service.SweeperInterval/2)
+	if err != nil {
+		s.logger.WarnContext(ctx, "sweeper failed to acquire leader lock", "error", err)
+		return
+	}
+	if !acquired {
+		s.logger.DebugContext(ctx, "sweeper lock held by another replica, skipping tick")
+		return
+	}
+
+	if result, err := s.SweepExpired(ctx); err != nil {
+		s.logger.WarnContext(ctx, "sweep expired failed", "error", err)
+	} else {
+		s.logger.InfoContext(ctx, "sweep expired completed", "touched", result.Touched)
+	}
+
+	if result, err := s.SweepLapsedCache(ctx); err != nil {
+		s.logger.WarnContext(ctx, "sweep lapsed cache failed", "error", err)
+	} else {
+		s.logger.InfoContext(ctx, "sweep lapsed cache completed", "touched", result.Touched)
+	}
+}
+
+func (s *sweeper) SweepExpired(ctx context.Context) (SweepResult, error) {
+	refs, err := s.urlRepo.FindExpired(ctx, s.config.Service.SweeperBatchSize)
+	if err != nil {
+		return SweepResult{Scope: "expired"}, err
+	}
+
+	touched := 0
+	for _, ref := range refs {
+		var applyErr error
+		if s.config.Service.ExpiredURLPolicy == "delete" {
+			applyErr = s.urlRepo.DeleteByShortCode(ctx, ref.ShortCode)
+		} else {
+			applyErr = s.urlRepo.DeactivateByShortCode(ctx, ref.ShortCode)
+		}
+		if applyErr != nil {
+			s.logger.WarnContext(ctx, "failed to apply expired URL policy", "shortCode", ref.ShortCode, "error", applyErr)
+			continue
+		}
+
+		if err := s.cacheInvalidator.PublishDeleted(ctx, ref.ShortCode); err != nil {
+			s.logger.WarnContext(ctx, "failed to publish invalidation for expired URL", "shortCode", ref.ShortCode, "error", err)
+		}
+
+		touched++
+	}
+
+	return SweepResult{Scope: "expired", Touched: touched}, nil
+}
+
+func (s *sweeper) SweepLapsedCache(ctx context.Context) (SweepResult, error) {
+	keys, err := s.cacheService.ScanKeys(ctx, s.cacheKeys.URL("*"))
+	if err != nil {
+		return SweepResult{Scope: "lapsed_cache"}, err
+	}
+
+	touched := 0
+	for _, key := range keys {
+		shortCode := s.cacheKeys.ShortCodeFromURLKey(key)
+		if shortCode == "" {
+			continue
+		}
+
+		urlData, err := s.urlRepo.FindByShortCode(ctx, shortCode)
+		if err == nil && urlData.IsActive {
+			continue
+		}
+
+		if err := s.cacheService.Delete(ctx, key); err != nil {
+			s.logger.WarnContext(ctx, "failed to remove lapsed cache entry", "key", key, "error", err)
+			continue
+		}
+
+		touched++
+	}
+
+	return SweepResult{Scope: "lapsed_cache", Touched: touched}, nil
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// ttlLRUCache is lruCache plus a per-entry expiry: it backs the L1 tier of
+// CacheService.GetOrLoad, where entries must age out on their own on top of
+// the usual capacity-based eviction and the explicit deletes
+// cache_invalidator.go makes through CacheService.InvalidateLocal.
+type ttlLRUCache struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List
+}
+
+type ttlLRUEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+func newTTLLRUCache(capacity int) *ttlLRUCache {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &ttlLRUCache{
+		capacity: capacity,
+		items:    make(map[string]*list.Element, capacity),
+		order:    list.New(),
+	}
+}
+
+// Get returns the cached value for key, or (nil, false) if it's absent or
+// its expiry has passed. An expired entry is evicted on the way out.
+func (c *ttlLRUCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := el.Value.(*ttlLRUEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.order.Remove(el)
+		delete(c.items, key)
+		return nil, false
+	}
+
+	c.order.MoveToFront(el)
+	return entry.value, true
+}
+
+func (c *ttlLRUCache) Set(key string, value []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := time.Now().Add(ttl)
+
+	if el, ok := c.items[key]; ok {
+		entry := el.Value.(*ttlLRUEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(el)
+		return
+	}
+
+	el := c.order.PushFront(&ttlLRUEntry{key: key, value: value, expiresAt: expiresAt})
+	c.items[key] = el
+
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		if oldest != nil {
+			c.order.Remove(oldest)
+			delete(c.items, oldest.Value.(*ttlLRUEntry).key)
+		}
+	}
+}
+
+func (c *ttlLRUCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.order.Remove(el)
+		delete(c.items, key)
+	}
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTTLLRUCache_EvictsLeastRecentlyUsedOverCapacity(t *testing.T) {
+	c := newTTLLRUCache(2)
+
+	c.Set("a", []byte("1"), time.Minute)
+	c.Set("b", []byte("2"), time.Minute)
+	if _, ok := c.Get("a"); !ok {
+		t.Fatalf("expected a to still be cached")
+	}
+
+	c.Set("c", []byte("3"), time.Minute)
+
+	if _, ok := c.Get("b"); ok {
+		t.Errorf("expected b to be evicted as least recently used")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Errorf("expected a to survive eviction since it was accessed most recently")
+	}
+	if _, ok := c.Get("c"); !ok {
+		t.Errorf("expected c to be cached")
+	}
+}
+
+func TestTTLLRUCache_ExpiresEntriesOnGet(t *testing.T) {
+	c := newTTLLRUCache(10)
+	c.Set("k", []byte("v"), time.Millisecond)
+
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok := c.Get("k"); ok {
+		t.Errorf("expected expired entry to be evicted on Get")
+	}
+}
+
+func TestTTLLRUCache_Delete(t *testing.T) {
+	c := newTTLLRUCache(10)
+	c.Set("k", []byte("v"), time.Minute)
+	c.Delete("k")
+
+	if _, ok := c.Get("k"); ok {
+		t.Errorf("expected deleted entry to be gone")
+	}
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/hoggir/re-path/redirect-service/internal/contract"
+	"github.com/hoggir/re-path/redirect-service/internal/domain"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// TypedCall wraps rpc.CallWithOptions with the contract registered under
+// contractName (see the contract package): it validates req against the
+// contract's request schema before publishing, attaches a contract_version
+// AMQP header so the Python side can negotiate, and validates the reply
+// against the response schema before decoding it into Resp. Go methods
+// can't take type parameters, so this is a free function taking rpc instead
+// of RabbitMQRPCService.TypedCall(...).
+//
+// contractName doubles as the RabbitMQ queue name, matching the existing
+// convention where a contract and its queue share one name (e.g.
+// "dashboard_request").
+func TypedCall[Req any, Resp any](ctx context.Context, rpc RabbitMQRPCService, contractName string, req Req, timeout time.Duration) (Resp, error) {
+	var zero Resp
+
+	reg, ok := contract.Lookup(contractName)
+	if !ok {
+		return zero, domain.ErrExternalService.
+			WithContext("reason", "unknown_contract").
+			WithContext("contract", contractName)
+	}
+
+	reqBody, err := json.Marshal(req)
+	if err != nil {
+		return zero, domain.ErrExternalService.
+			WithContext("reason", "marshal_failed").
+			WithContext("contract", contractName).
+			Wrap(err)
+	}
+
+	if err := reg.RequestSchema.Validate(reqBody); err != nil {
+		return zero, domain.ErrExternalService.
+			WithContext("reason", "schema_drift").
+			WithContext("contract", contractName).
+			Wrap(err)
+	}
+
+	opts := DefaultCallOptions
+	opts.Headers = amqp.Table{"contract_version": reg.Version}
+
+	respBody, err := rpc.CallWithOptions(ctx, contractName, json.RawMessage(reqBody), timeout, opts)
+	if err != nil {
+		return zero, err
+	}
+
+	if err := reg.ResponseSchema.Validate(respBody); err != nil {
+		return zero, domain.ErrExternalService.
+			WithContext("reason", "schema_drift").
+			WithContext("contract", contractName).
+			Wrap(err)
+	}
+
+	var resp Resp
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return zero, domain.ErrExternalService.
+			WithContext("reason", "unmarshal_failed").
+			WithContext("contract", contractName).
+			Wrap(err)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,25 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/hoggir/re-path/redirect-service/internal/config"
+	"github.com/hoggir/re-path/redirect-service/internal/database"
+	"github.com/hoggir/re-path/redirect-service/internal/logger"
+)
+
+// NewManager selects a Manager based on Config.Storage.Driver. mongoDB is the
+// connection already opened at bootstrap (ClickEventRepository also depends on
+// it directly), so the mongodb driver just wraps it rather than reconnecting.
+func NewManager(cfg *config.Config, mongoDB *database.MongoDB, log logger.Logger) (Manager, error) {
+	switch cfg.Storage.Driver {
+	case "postgres":
+		return NewPostgresManager(cfg, log)
+	case "memory":
+		return NewMemoryManager(), nil
+	case "mongodb", "":
+		return NewMongoManager(mongoDB, cfg), nil
+	default:
+		return nil, fmt.Errorf("storage: unknown driver %q", cfg.Storage.Driver)
+	}
+}
@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hoggir/re-path/redirect-service/internal/domain"
+)
+
+// memoryManager backs URLStore with a plain in-process map, so unit tests
+// don't need to spin up a MongoDB or Postgres container.
+type memoryManager struct {
+	store *memoryURLStore
+}
+
+func NewMemoryManager() Manager {
+	return &memoryManager{
+		store: &memoryURLStore{urls: make(map[string]*memoryURL)},
+	}
+}
+
+func (m *memoryManager) URLStore() URLStore {
+	return m.store
+}
+
+func (m *memoryManager) HealthCheck(ctx context.Context) error {
+	return nil
+}
+
+func (m *memoryManager) Close() error {
+	return nil
+}
+
+type memoryURL struct {
+	domain.FindByShortCode
+	ExpiresAt *time.Time
+}
+
+type memoryURLStore struct {
+	mu   sync.RWMutex
+	urls map[string]*memoryURL
+}
+
+// Seed lets tests populate the store directly, bypassing the URLStore
+// interface that only exposes what the redirect path needs.
+func (s *memoryURLStore) Seed(shortCode string, url domain.FindByShortCode) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.urls[shortCode] = &memoryURL{FindByShortCode: url, ExpiresAt: url.ExpiresAt}
+}
+
+func (s *memoryURLStore) FindByShortCode(ctx context.Context, shortCode string) (*domain.FindByShortCode, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, ok := s.urls[shortCode]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	url := entry.FindByShortCode
+	return &url, nil
+}
+
+func (s *memoryURLStore) IncrementClickCount(ctx context.Context, shortCode string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.urls[shortCode]; !ok {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// IncrementClickCounts mirrors IncrementClickCount's semantics for each
+// shortCode in the batch: it's a liveness check only, since FindByShortCode's
+// projection doesn't carry a click count for the in-memory store to mutate.
+func (s *memoryURLStore) IncrementClickCounts(ctx context.Context, deltas map[string]int) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for shortCode := range deltas {
+		if _, ok := s.urls[shortCode]; !ok {
+			return ErrNotFound
+		}
+	}
+
+	return nil
+}
+
+func (s *memoryURLStore) FindExpired(ctx context.Context, limit int64) ([]domain.ExpiredURLRef, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var refs []domain.ExpiredURLRef
+	now := time.Now()
+	for shortCode, entry := range s.urls {
+		if !entry.IsActive || entry.ExpiresAt == nil || entry.ExpiresAt.After(now) {
+			continue
+		}
+		refs = append(refs, domain.ExpiredURLRef{ShortCode: shortCode, UserID: entry.UserID})
+		if int64(len(refs)) >= limit {
+			break
+		}
+	}
+
+	return refs, nil
+}
+
+func (s *memoryURLStore) DeactivateByShortCode(ctx context.Context, shortCode string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.urls[shortCode]
+	if !ok {
+		return ErrNotFound
+	}
+	entry.IsActive = false
+	return nil
+}
+
+func (s *memoryURLStore) DeleteByShortCode(ctx context.Context, shortCode string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.urls, shortCode)
+	return nil
+}
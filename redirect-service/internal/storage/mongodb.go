@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/hoggir/re-path/redirect-service/internal/config"
+	"github.com/hoggir/re-path/redirect-service/internal/database"
+	"github.com/hoggir/re-path/redirect-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoManager struct {
+	db    *database.MongoDB
+	cfg   *config.Config
+	store *mongoURLStore
+}
+
+func NewMongoManager(db *database.MongoDB, cfg *config.Config) Manager {
+	return &mongoManager{
+		db:  db,
+		cfg: cfg,
+		store: &mongoURLStore{
+			collection: db.Collection(domain.URL{}.CollectionName()),
+			dbCtx:      database.NewDBContext(cfg),
+		},
+	}
+}
+
+func (m *mongoManager) URLStore() URLStore {
+	return m.store
+}
+
+func (m *mongoManager) HealthCheck(ctx context.Context) error {
+	return m.db.Client.Ping(ctx, nil)
+}
+
+// Close is a no-op here: lifecycle for the shared *database.MongoDB connection
+// is still owned by main's defer, since ClickEventRepository also depends on
+// it directly.
+func (m *mongoManager) Close() error {
+	return nil
+}
+
+type mongoURLStore struct {
+	collection *mongo.Collection
+	dbCtx      *database.DBContext
+}
+
+func (s *mongoURLStore) FindByShortCode(ctx context.Context, shortCode string) (*domain.FindByShortCode, error) {
+	readCtx, cancel := s.dbCtx.Read(ctx)
+	defer cancel()
+	defer s.dbCtx.Observe("FindByShortCode", time.Now())
+
+	var url domain.FindByShortCode
+
+	filter := bson.M{"shortCode": shortCode}
+	projection := bson.M{"userId": 1, "originalUrl": 1, "isActive": 1, "expiresAt": 1, "_id": 0}
+
+	err := s.collection.FindOne(readCtx, filter, options.FindOne().SetProjection(projection)).Decode(&url)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &url, nil
+}
+
+func (s *mongoURLStore) IncrementClickCount(ctx context.Context, shortCode string) error {
+	writeCtx, cancel := s.dbCtx.Write(ctx)
+	defer cancel()
+	defer s.dbCtx.Observe("IncrementClickCount", time.Now())
+
+	filter := bson.M{"shortCode": shortCode}
+	update := bson.M{
+		"$inc": bson.M{"clickCount": 1},
+		"$set": bson.M{"updatedAt": time.Now()},
+	}
+
+	result, err := s.collection.UpdateOne(writeCtx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// IncrementClickCounts applies one $inc per shortCode as a single bulk write,
+// so a burst of batched clicks costs one round trip instead of N.
+func (s *mongoURLStore) IncrementClickCounts(ctx context.Context, deltas map[string]int) error {
+	if len(deltas) == 0 {
+		return nil
+	}
+
+	writeCtx, cancel := s.dbCtx.Write(ctx)
+	defer cancel()
+	defer s.dbCtx.Observe("IncrementClickCounts", time.Now())
+
+	now := time.Now()
+	models := make([]mongo.WriteModel, 0, len(deltas))
+	for shortCode, delta := range deltas {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"shortCode": shortCode}).
+			SetUpdate(bson.M{
+				"$inc": bson.M{"clickCount": delta},
+				"$set": bson.M{"updatedAt": now},
+			}))
+	}
+
+	_, err := s.collection.BulkWrite(writeCtx, models, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+func (s *mongoURLStore) FindExpired(ctx context.Context, limit int64) ([]domain.ExpiredURLRef, error) {
+	aggCtx, cancel := s.dbCtx.Aggregate(ctx)
+	defer cancel()
+	defer s.dbCtx.Observe("FindExpired", time.Now())
+
+	filter := bson.M{
+		"isActive":  true,
+		"expiresAt": bson.M{"$lt": time.Now()},
+	}
+
+	opts := options.Find().
+		SetProjection(bson.M{"shortCode": 1, "userId": 1, "_id": 0}).
+		SetLimit(limit)
+
+	cursor, err := s.collection.Find(aggCtx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(aggCtx)
+
+	var refs []domain.ExpiredURLRef
+	if err := cursor.All(aggCtx, &refs); err != nil {
+		return nil, err
+	}
+
+	return refs, nil
+}
+
+func (s *mongoURLStore) DeactivateByShortCode(ctx context.Context, shortCode string) error {
+	writeCtx, cancel := s.dbCtx.Write(ctx)
+	defer cancel()
+	defer s.dbCtx.Observe("DeactivateByShortCode", time.Now())
+
+	filter := bson.M{"shortCode": shortCode}
+	update := bson.M{"$set": bson.M{"isActive": false, "updatedAt": time.Now()}}
+
+	_, err := s.collection.UpdateOne(writeCtx, filter, update)
+	return err
+}
+
+func (s *mongoURLStore) DeleteByShortCode(ctx context.Context, shortCode string) error {
+	writeCtx, cancel := s.dbCtx.Write(ctx)
+	defer cancel()
+	defer s.dbCtx.Observe("DeleteByShortCode", time.Now())
+
+	_, err := s.collection.DeleteOne(writeCtx, bson.M{"shortCode": shortCode})
+	return err
+}
@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hoggir/re-path/redirect-service/internal/config"
+	"github.com/hoggir/re-path/redirect-service/internal/domain"
+	"github.com/hoggir/re-path/redirect-service/internal/logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// postgresManager is the Postgres-backed Manager, the most useful second
+// backend given how much of this product's value is in the analytics side.
+type postgresManager struct {
+	pool   *pgxpool.Pool
+	store  *postgresURLStore
+	logger logger.Logger
+}
+
+func NewPostgresManager(cfg *config.Config, log logger.Logger) (Manager, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.MongoDB.ConnTimeout)
+	defer cancel()
+
+	pool, err := pgxpool.New(ctx, cfg.Storage.PostgresURI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to postgres: %w", err)
+	}
+
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("failed to ping postgres: %w", err)
+	}
+
+	log.Info("Postgres storage connected successfully")
+
+	return &postgresManager{
+		pool:   pool,
+		store:  &postgresURLStore{pool: pool},
+		logger: log,
+	}, nil
+}
+
+func (m *postgresManager) URLStore() URLStore {
+	return m.store
+}
+
+func (m *postgresManager) HealthCheck(ctx context.Context) error {
+	return m.pool.Ping(ctx)
+}
+
+func (m *postgresManager) Close() error {
+	m.pool.Close()
+	return nil
+}
+
+type postgresURLStore struct {
+	pool *pgxpool.Pool
+}
+
+func (s *postgresURLStore) FindByShortCode(ctx context.Context, shortCode string) (*domain.FindByShortCode, error) {
+	var url domain.FindByShortCode
+
+	query := `SELECT user_id, original_url, is_active, expires_at FROM urls WHERE short_code = $1`
+	err := s.pool.QueryRow(ctx, query, shortCode).Scan(&url.UserID, &url.OriginalURL, &url.IsActive, &url.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &url, nil
+}
+
+func (s *postgresURLStore) IncrementClickCount(ctx context.Context, shortCode string) error {
+	query := `UPDATE urls SET click_count = click_count + 1, updated_at = $2 WHERE short_code = $1`
+	tag, err := s.pool.Exec(ctx, query, shortCode, time.Now())
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// IncrementClickCounts applies the batch inside a single transaction so a
+// partial failure can't leave some short codes counted and others not.
+func (s *postgresURLStore) IncrementClickCounts(ctx context.Context, deltas map[string]int) error {
+	if len(deltas) == 0 {
+		return nil
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	now := time.Now()
+	query := `UPDATE urls SET click_count = click_count + $3, updated_at = $2 WHERE short_code = $1`
+	batch := &pgx.Batch{}
+	for shortCode, delta := range deltas {
+		batch.Queue(query, shortCode, now, delta)
+	}
+
+	results := tx.SendBatch(ctx, batch)
+	for range deltas {
+		if _, err := results.Exec(); err != nil {
+			results.Close()
+			return err
+		}
+	}
+	if err := results.Close(); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (s *postgresURLStore) FindExpired(ctx context.Context, limit int64) ([]domain.ExpiredURLRef, error) {
+	query := `SELECT short_code, user_id FROM urls WHERE is_active = true AND expires_at < now() LIMIT $1`
+	rows, err := s.pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var refs []domain.ExpiredURLRef
+	for rows.Next() {
+		var ref domain.ExpiredURLRef
+		if err := rows.Scan(&ref.ShortCode, &ref.UserID); err != nil {
+			return nil, err
+		}
+		refs = append(refs, ref)
+	}
+
+	return refs, rows.Err()
+}
+
+func (s *postgresURLStore) DeactivateByShortCode(ctx context.Context, shortCode string) error {
+	query := `UPDATE urls SET is_active = false, updated_at = $2 WHERE short_code = $1`
+	_, err := s.pool.Exec(ctx, query, shortCode, time.Now())
+	return err
+}
+
+func (s *postgresURLStore) DeleteByShortCode(ctx context.Context, shortCode string) error {
+	_, err := s.pool.Exec(ctx, `DELETE FROM urls WHERE short_code = $1`, shortCode)
+	return err
+}
@@ -0,0 +1,7 @@
+package storage
+
+// NewURLStore unwraps the URLStore owned by a Manager, so wire can provide it
+// directly as a NewURLRepository dependency.
+func NewURLStore(manager Manager) URLStore {
+	return manager.URLStore()
+}
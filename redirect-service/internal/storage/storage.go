@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hoggir/re-path/redirect-service/internal/domain"
+)
+
+// ErrNotFound is returned by any URLStore when no document matches; adapters
+// translate their driver-specific "no rows" error into this so callers never
+// need to import mongo/pgx/etc. to check for it.
+var ErrNotFound = errors.New("storage: not found")
+
+// URLStore is the storage-layer contract a URLRepository needs. It exposes
+// just enough shape for each adapter to build its own optimal query - the
+// Mongo adapter still does BSON projection, the Postgres one a column list.
+type URLStore interface {
+	FindByShortCode(ctx context.Context, shortCode string) (*domain.FindByShortCode, error)
+	IncrementClickCount(ctx context.Context, shortCode string) error
+	// IncrementClickCounts applies a batch of click-count deltas in one
+	// round trip, so a burst of clicks on a viral link doesn't turn into a
+	// burst of individual writes.
+	IncrementClickCounts(ctx context.Context, deltas map[string]int) error
+	FindExpired(ctx context.Context, limit int64) ([]domain.ExpiredURLRef, error)
+	DeactivateByShortCode(ctx context.Context, shortCode string) error
+	DeleteByShortCode(ctx context.Context, shortCode string) error
+}
+
+// Manager owns a storage backend's lifecycle: the store itself, a way to
+// verify it's reachable, and how to shut it down cleanly.
+type Manager interface {
+	URLStore() URLStore
+	HealthCheck(ctx context.Context) error
+	Close() error
+}
@@ -0,0 +1,71 @@
+// Package tracing sets up the process-wide OpenTelemetry TracerProvider and
+// exposes the single Tracer the rest of the service pulls spans from, so
+// RedirectService, CacheService, and the repository layer all contribute
+// spans to whatever trace middleware.RequestContextMiddleware started for
+// the current request.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hoggir/re-path/redirect-service/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/hoggir/re-path/redirect-service"
+
+// Tracer returns the service's single Tracer. It's safe to call before
+// NewProvider runs (e.g. in tests) - otel.Tracer falls back to a no-op
+// implementation until a real TracerProvider is registered.
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// NewProvider builds and registers the global TracerProvider, exporting
+// spans via OTLP/gRPC to cfg.Tracing.OTLPEndpoint. When tracing is disabled,
+// it registers a no-op provider so every Tracer() call downstream stays
+// cheap without callers needing to check a feature flag themselves.
+func NewProvider(cfg *config.Config) (*sdktrace.TracerProvider, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if !cfg.Tracing.Enabled {
+		provider := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.NeverSample()))
+		otel.SetTracerProvider(provider)
+		return provider, nil
+	}
+
+	exporter, err := otlptracegrpc.New(context.Background(),
+		otlptracegrpc.WithEndpoint(cfg.Tracing.OTLPEndpoint),
+		otlptracegrpc.WithInsecure(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create OTLP trace exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName(cfg.Tracing.ServiceName),
+		semconv.DeploymentEnvironment(cfg.App.Env),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("merge OTEL resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.Tracing.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider, nil
+}